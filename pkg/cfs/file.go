@@ -1,6 +1,7 @@
 package cfs
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -94,6 +95,45 @@ func CopyFile(src, dest string, opts ...Option) error {
 	return nil
 }
 
+// CopyDir copies recursively all files and directories from srcdir into destdir.
+//
+// Files are read from the configured fs.FS (see WithFS, e.g. an embed.FS) and written on the OS filesystem.
+// Source paths are built with the configured Join function (see WithJoin, filepath.Join by default),
+// as such path.Join must be given when reading from an embed.FS on windows.
+//
+// Copied files permissions are the ones given with WithPerm (0o644 by default)
+// and created directories permissions are 0o755.
+func CopyDir(srcdir, destdir string, opts ...Option) error {
+	o := newOpt(opts...)
+
+	entries, err := fs.ReadDir(o.fsys, srcdir)
+	if err != nil {
+		return fmt.Errorf("read dir: %w", err)
+	}
+
+	if err := os.MkdirAll(destdir, RwxRxRxRx); err != nil {
+		return fmt.Errorf("mkdir all: %w", err)
+	}
+
+	errs := make([]error, 0, len(entries))
+	for _, entry := range entries {
+		src := o.join(srcdir, entry.Name())
+		dest := filepath.Join(destdir, entry.Name())
+
+		// handle directories
+		if entry.IsDir() {
+			errs = append(errs, CopyDir(src, dest, opts...))
+			continue
+		}
+
+		// handle files
+		if err := CopyFile(src, dest, opts...); err != nil {
+			errs = append(errs, fmt.Errorf("copy file: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // SafeMove moves src into dest while taking care of potentially running process for dest.
 func SafeMove(src, dest string, opts ...Option) error {
 	o := newOpt(opts...)
@@ -2,13 +2,16 @@ package cfs_test
 
 import (
 	"os"
+	"path"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs/tests"
 )
 
 func TestCopyFile(t *testing.T) {
@@ -62,6 +65,70 @@ func TestCopyFile(t *testing.T) {
 	})
 }
 
+func TestCopyDir(t *testing.T) {
+	t.Run("error_srcdir_not_exists", func(t *testing.T) {
+		// Arrange
+		srcdir := filepath.Join(t.TempDir(), "invalid")
+		destdir := filepath.Join(t.TempDir(), "copy")
+
+		// Act
+		err := cfs.CopyDir(srcdir, destdir)
+
+		// Assert
+		assert.ErrorContains(t, err, "read dir")
+		assert.NoDirExists(t, destdir)
+	})
+
+	t.Run("error_mkdir_all", func(t *testing.T) {
+		// Arrange
+		srcdir := t.TempDir()
+		destdir := filepath.Join(t.TempDir(), "file.txt")
+		require.NoError(t, os.WriteFile(destdir, []byte("hey file"), cfs.RwRR))
+
+		// Act
+		err := cfs.CopyDir(srcdir, destdir)
+
+		// Assert
+		assert.ErrorContains(t, err, "mkdir all")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Arrange
+		srcdir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(srcdir, "file.txt"), []byte("hey file"), cfs.RwRR))
+		require.NoError(t, os.MkdirAll(filepath.Join(srcdir, "subdir", "empty"), cfs.RwxRxRxRx))
+		require.NoError(t, os.WriteFile(filepath.Join(srcdir, "subdir", "file.txt"), []byte("hey subfile"), cfs.RwRR))
+		destdir := filepath.Join(t.TempDir(), "copy")
+
+		// Act
+		err := cfs.CopyDir(srcdir, destdir)
+
+		// Assert
+		require.NoError(t, err)
+		assert.NoError(t, tests.EqualDirs(srcdir, destdir))
+		assert.DirExists(t, filepath.Join(destdir, "subdir", "empty"))
+	})
+
+	t.Run("success_with_fs", func(t *testing.T) {
+		// Arrange
+		fsys := fstest.MapFS{
+			"templates/file.txt":        {Data: []byte("hey file")},
+			"templates/subdir/file.txt": {Data: []byte("hey subfile")},
+		}
+		destdir := t.TempDir()
+
+		// Act
+		err := cfs.CopyDir("templates", destdir, cfs.WithFS(fsys), cfs.WithJoin(path.Join))
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(filepath.Join(destdir, "subdir", "file.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("hey subfile"), bytes)
+		assert.FileExists(t, filepath.Join(destdir, "file.txt"))
+	})
+}
+
 func TestExists(t *testing.T) {
 	t.Run("false_not_exists", func(t *testing.T) {
 		// Arrange
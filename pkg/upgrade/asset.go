@@ -0,0 +1,139 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// errAssetNotFound is the error returned when no release asset matches asset templates.
+var errAssetNotFound = errors.New("no valid release asset found")
+
+// _aliases maps GOOS and GOARCH values to their usual alternative names in release assets.
+var _aliases = map[string][]string{
+	"386":    {"i386", "i686", "x86"},
+	"amd64":  {"x86_64", "x64", "x86-64"},
+	"arm64":  {"aarch64"},
+	"darwin": {"macos", "osx"},
+}
+
+// _metadataSuffixes are the suffixes of release assets which can't be installed (checksums, signatures, etc.)
+// and as such are never matched by glob or regexp patterns.
+var _metadataSuffixes = []string{".asc", ".intoto.jsonl", ".json", ".md5", ".minisig", ".pem", ".sbom", ".sha1", ".sha256", ".sha512", ".sig", "checksums.txt", "sums"}
+
+// matchAsset renders all asset templates (see WithAssetTemplates) and returns the first release asset matching one of them (in order).
+//
+// Each rendered template is matched as a regexp when it starts with 're:', as a glob when it contains '*', '?' or '[',
+// or exactly otherwise. When no asset matches literally, exact and glob patterns are tried again case insensitively
+// and with GOOS and GOARCH aliases (e.g. x86_64 for amd64).
+//
+// Each template is also rendered with lower GOAMD64 levels (e.g. 'v3', then 'v2' and 'v1') when the host one doesn't match,
+// in which case templateData 'GOAMD64' is updated with the matching level.
+//
+// An error wrapping errAssetNotFound and listing all candidate assets is returned when no asset matches.
+func matchAsset(ro runOptions, release *Release, templateData map[string]any) (Asset, error) {
+	level, _ := templateData["GOAMD64"].(string)
+	data := maps.Clone(templateData)
+
+	patterns := make([]string, 0, len(ro.assetTemplates))
+	for _, assetTemplate := range ro.assetTemplates {
+		for _, fallback := range goamd64Fallbacks(level) {
+			data["GOAMD64"] = fallback
+			pattern, err := getTemplateValue(assetTemplate, data)
+			if err != nil {
+				return Asset{}, fmt.Errorf("get asset name: %w", err)
+			}
+			if slices.Contains(patterns, pattern) {
+				continue // template doesn't use GOAMD64 (or was already given)
+			}
+			patterns = append(patterns, pattern)
+
+			matches, err := matchers(pattern)
+			if err != nil {
+				return Asset{}, err
+			}
+			for _, match := range matches {
+				for _, asset := range release.Assets {
+					if match(asset.Name) {
+						templateData["GOAMD64"] = fallback
+						return asset, nil
+					}
+				}
+			}
+		}
+	}
+
+	candidates := make([]string, 0, len(release.Assets))
+	for _, asset := range release.Assets {
+		candidates = append(candidates, asset.Name)
+	}
+	return Asset{}, fmt.Errorf("%w matching '%s' (candidates: %s)", errAssetNotFound, strings.Join(patterns, "', '"), strings.Join(candidates, ", "))
+}
+
+// matchers returns the matching functions of the input pattern (see matchAsset),
+// ordered from the strictest one (literal) to the most relaxed one (case insensitive with aliases).
+func matchers(pattern string) ([]func(name string) bool, error) {
+	if expr, ok := strings.CutPrefix(pattern, "re:"); ok {
+		re, err := regexp.Compile("^(?i:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset regexp '%s': %w", expr, err)
+		}
+		return []func(string) bool{func(name string) bool { return !isMetadata(name) && re.MatchString(name) }}, nil
+	}
+
+	variants := aliases(strings.ToLower(pattern))
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []func(string) bool{
+			func(name string) bool { return name == pattern },
+			func(name string) bool { return slices.Contains(variants, strings.ToLower(name)) },
+		}, nil
+	}
+
+	for _, variant := range variants {
+		if _, err := path.Match(variant, ""); err != nil {
+			return nil, fmt.Errorf("invalid asset glob '%s': %w", pattern, err)
+		}
+	}
+	return []func(string) bool{
+		func(name string) bool {
+			ok, _ := path.Match(pattern, name)
+			return ok && !isMetadata(name)
+		},
+		func(name string) bool {
+			if isMetadata(name) {
+				return false
+			}
+			return slices.ContainsFunc(variants, func(variant string) bool {
+				ok, _ := path.Match(variant, strings.ToLower(name))
+				return ok
+			})
+		},
+	}, nil
+}
+
+// aliases returns the input pattern alongside all its variants with GOOS and GOARCH aliases.
+func aliases(pattern string) []string {
+	variants := []string{pattern}
+	for _, value := range []string{runtime.GOOS, runtime.GOARCH} {
+		for _, variant := range variants {
+			if !strings.Contains(variant, value) {
+				continue
+			}
+			for _, alias := range _aliases[value] {
+				variants = append(variants, strings.ReplaceAll(variant, value, alias))
+			}
+		}
+	}
+	return variants
+}
+
+// isMetadata returns true when the input asset name is a checksums file, a signature, etc.
+func isMetadata(name string) bool {
+	name = strings.ToLower(name)
+	return slices.ContainsFunc(_metadataSuffixes, func(suffix string) bool { return strings.HasSuffix(name, suffix) })
+}
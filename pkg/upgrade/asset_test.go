@@ -0,0 +1,235 @@
+package upgrade_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v63/github"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+func TestAssetMatching(t *testing.T) {
+	ctx := context.Background()
+
+	httpClient := cleanhttp.DefaultClient()
+	httpmock.ActivateNonDefault(httpClient)
+	t.Cleanup(httpmock.DeactivateAndReset)
+
+	getReleases := upgrade.GithubReleases("owner", "repo")
+	releasesURL := "https://api.github.com/repos/owner/repo/releases?page=1&per_page=100"
+
+	// respond registers a single release v1.2.0 with the input assets names
+	respond := func(names ...string) {
+		assets := make([]*github.ReleaseAsset, 0, len(names))
+		for _, name := range names {
+			assets = append(assets, &github.ReleaseAsset{Name: toPtr(name), BrowserDownloadURL: toPtr("http://example.com/" + name)})
+		}
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{{TagName: toPtr("v1.2.0"), Assets: assets}}))
+	}
+
+	t.Run("error_invalid_regexp", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		respond("repo.tar.gz")
+
+		// Act
+		_, err := upgrade.Check(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplate("re:repo_(.tar.gz"),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		assert.ErrorContains(t, err, "invalid asset regexp")
+	})
+
+	t.Run("error_invalid_glob", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		respond("repo.tar.gz")
+
+		// Act
+		_, err := upgrade.Check(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplate("repo_[*.tar.gz"),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		assert.ErrorContains(t, err, "invalid asset glob")
+	})
+
+	t.Run("error_no_match", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{
+				{
+					TagName: toPtr("v1.2.0"),
+					Assets: []*github.ReleaseAsset{
+						{Name: toPtr("repo_plan9_mips.tar.gz"), BrowserDownloadURL: toPtr("some URL")},
+						{Name: toPtr("checksums.txt"), BrowserDownloadURL: toPtr("some URL")},
+					},
+				},
+			}))
+
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplates("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz", "{{ .Repo }}_*.zip"),
+			upgrade.WithDestination(t.TempDir()),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		assert.ErrorContains(t, err, fmt.Sprintf("no valid release asset found matching 'repo_%s_%s.tar.gz', 'repo_*.zip'", runtime.GOOS, runtime.GOARCH))
+		assert.ErrorContains(t, err, "(candidates: repo_plan9_mips.tar.gz, checksums.txt)")
+	})
+
+	t.Run("success_case_insensitive", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		name := strings.ToUpper(fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH))
+		respond(name)
+
+		// Act
+		result, err := upgrade.Check(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz"),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, name, result.AssetName)
+	})
+
+	t.Run("success_literal_first", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		name := fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+		respond(strings.ToUpper(name), name)
+
+		// Act
+		result, err := upgrade.Check(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz"),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, name, result.AssetName)
+	})
+
+	t.Run("success_glob", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		name := fmt.Sprintf("repo_1.2.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+		respond("checksums.txt", name+".sha256", name)
+
+		// Act
+		result, err := upgrade.Check(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplate("{{ .Repo }}_*_{{ .GOOS }}_{{ .GOARCH }}*"),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, name, result.AssetName)
+		assert.Equal(t, "http://example.com/"+name, result.AssetURL)
+	})
+
+	t.Run("success_regexp", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		name := fmt.Sprintf("repo-v1.2.0-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+		respond(name+".sig", "repo-v1.2.0-plan9-mips.tar.gz", name)
+
+		// Act
+		result, err := upgrade.Check(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplate(`re:{{ .Repo }}-v?[0-9.]+-{{ .GOOS }}-{{ .GOARCH }}\.tar\.gz`),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, name, result.AssetName)
+	})
+
+	t.Run("success_alias", func(t *testing.T) {
+		aliases := map[string]string{"386": "i686", "amd64": "x86_64", "arm64": "aarch64"}
+		alias, ok := aliases[runtime.GOARCH]
+		if !ok {
+			t.Skip("no alias for current GOARCH")
+		}
+
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		name := fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, alias)
+		respond(name)
+
+		// Act
+		result, err := upgrade.Check(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz"),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, name, result.AssetName)
+	})
+
+	t.Run("success_fallback_template", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		name := fmt.Sprintf("repo-%s-%s", runtime.GOOS, runtime.GOARCH)
+		respond("repo_plan9_mips.tar.gz", name)
+
+		// Act
+		result, err := upgrade.Check(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplates("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}{{ .ArchiveExt }}", "{{ .Repo }}-{{ .GOOS }}-{{ .GOARCH }}"),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, name, result.AssetName)
+	})
+
+	t.Run("success_goamd64_fallback", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		goamd64 := *upgrade.GOAMD64
+		t.Cleanup(func() { *upgrade.GOAMD64 = goamd64 })
+		*upgrade.GOAMD64 = func() string { return "v3" }
+
+		name := fmt.Sprintf("repo_%s_%s_v2.tar.gz", runtime.GOOS, runtime.GOARCH)
+		respond(fmt.Sprintf("repo_%s_%s_v1.tar.gz", runtime.GOOS, runtime.GOARCH), name)
+
+		// Act
+		result, err := upgrade.Check(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}_{{ .GOAMD64 }}.tar.gz"),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, name, result.AssetName)
+	})
+
+	t.Run("success_libc", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		libc := *upgrade.Libc
+		t.Cleanup(func() { *upgrade.Libc = libc })
+		*upgrade.Libc = func() string { return "musl" }
+
+		name := fmt.Sprintf("repo_%s_%s-musl.tar.gz", runtime.GOOS, runtime.GOARCH)
+		respond(fmt.Sprintf("repo_%s_%s-gnu.tar.gz", runtime.GOOS, runtime.GOARCH), name)
+
+		// Act
+		result, err := upgrade.Check(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}{{ if .Libc }}-{{ .Libc }}{{ end }}.tar.gz"),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, name, result.AssetName)
+	})
+}
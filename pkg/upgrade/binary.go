@@ -0,0 +1,99 @@
+package upgrade
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+)
+
+// findBinaries returns the files (slash separated and relative to dir) to install from the downloaded asset directory.
+//
+// When no patterns are given (see WithBinaryPath), the first file named as one of defaults (at any depth) is returned.
+// Otherwise, all files matching patterns (see matchPath) are returned in patterns order and each pattern must match at least one file.
+func findBinaries(dir string, patterns, defaults []string) ([]string, error) {
+	files, err := walkFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(patterns) == 0 {
+		for _, name := range defaults {
+			if index := slices.IndexFunc(files, func(file string) bool { return path.Base(file) == name }); index >= 0 {
+				return []string{files[index]}, nil
+			}
+		}
+		return nil, fmt.Errorf("unable to determine binary to install (files: %s), it can be specified with WithBinaryPath", strings.Join(files, ", "))
+	}
+
+	var binaries []string
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid binary path '%s': %w", pattern, err)
+		}
+		matched := slices.DeleteFunc(slices.Clone(files), func(file string) bool { return !matchPath(pattern, file) })
+		if len(matched) == 0 {
+			return nil, fmt.Errorf("no file matching binary path '%s' (files: %s)", pattern, strings.Join(files, ", "))
+		}
+		for _, file := range matched {
+			if !slices.Contains(binaries, file) {
+				binaries = append(binaries, file)
+			}
+		}
+	}
+	return binaries, nil
+}
+
+// matchPath returns true when the input file (slash separated and relative) matches the glob pattern.
+//
+// The whole file path is matched when the pattern contains a '/' (e.g. '*/bin/repo'), only the file name otherwise (e.g. 'repo*').
+func matchPath(pattern, file string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if !strings.Contains(pattern, "/") {
+		file = path.Base(file)
+	}
+	ok, _ := path.Match(pattern, file)
+	return ok
+}
+
+// walkFiles returns all regular files (slash separated and relative to dir) in dir and its subdirectories, in lexical order.
+func walkFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk dir: %w", err)
+	}
+	return files, nil
+}
+
+// otherBinaries returns the binaries following the first one (relative to the downloaded asset directory)
+// as companion files to install next to dest with their own name.
+//
+// They're staged and committed alongside companions (see stageCompanions) to avoid half upgraded installations.
+func otherBinaries(binaries []string, dest string) []companionFile {
+	if len(binaries) <= 1 {
+		return nil
+	}
+	others := make([]companionFile, 0, len(binaries)-1)
+	for _, binary := range binaries[1:] {
+		others = append(others, companionFile{dest: filepath.Join(filepath.Dir(dest), path.Base(binary)), perm: cfs.RwxRxRxRx, src: binary})
+	}
+	return others
+}
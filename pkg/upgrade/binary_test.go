@@ -0,0 +1,168 @@
+package upgrade_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+// writeArchive writes a tar.gz archive at dest with the input files (slash separated name to content).
+func writeArchive(t *testing.T, dest string, files map[string]string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(dest), cfs.RwxRxRxRx))
+	file, err := os.Create(dest)
+	require.NoError(t, err)
+	defer file.Close()
+
+	gw := gzip.NewWriter(file)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+}
+
+// runDir runs an upgrade of repo with getReleases in dest with '{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz' asset template
+// and '{{ .Repo }}' target template, both being overridable with the input options.
+func runDir(t *testing.T, getReleases upgrade.GetReleases, dest string, opts ...upgrade.RunOption) error {
+	t.Helper()
+	opts = append([]upgrade.RunOption{
+		upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz"),
+		upgrade.WithDestination(dest),
+		upgrade.WithTargetTemplate("{{ .Repo }}"),
+	}, opts...)
+	_, err := upgrade.Run(context.Background(), "repo", "", getReleases, opts...)
+	return err
+}
+
+func TestBinaryPath(t *testing.T) {
+	ctx := context.Background()
+
+	root := t.TempDir()
+	asset := fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	nested := fmt.Sprintf("repo_v1.2.3_%s_%s", runtime.GOOS, runtime.GOARCH)
+	writeArchive(t, filepath.Join(root, "v1.2.3", asset), map[string]string{
+		nested + "/LICENSE":         "license",
+		nested + "/bin/repo":        "repo binary",
+		nested + "/bin/repo-server": "server binary",
+	})
+	getReleases := upgrade.DirReleases(root)
+
+	t.Run("error_invalid_binary_path", func(t *testing.T) {
+		// Act
+		err := runDir(t, getReleases, t.TempDir(), upgrade.WithBinaryPath("{{ func }}"))
+
+		// Assert
+		assert.ErrorContains(t, err, "get binary path")
+	})
+
+	t.Run("error_invalid_glob", func(t *testing.T) {
+		// Act
+		err := runDir(t, getReleases, t.TempDir(), upgrade.WithBinaryPath("bin/[*"))
+
+		// Assert
+		assert.ErrorContains(t, err, "invalid binary path 'bin/[*'")
+	})
+
+	t.Run("error_no_match", func(t *testing.T) {
+		// Act
+		err := runDir(t, getReleases, t.TempDir(), upgrade.WithBinaryPath("repo", "repo-agent"))
+
+		// Assert
+		assert.ErrorContains(t, err, "no file matching binary path 'repo-agent'")
+		assert.ErrorContains(t, err, nested+"/bin/repo-server")
+	})
+
+	t.Run("error_undetermined_binary", func(t *testing.T) {
+		// Arrange
+		root := t.TempDir()
+		writeArchive(t, filepath.Join(root, "v1.2.3", asset), map[string]string{"bin/other": "other binary"})
+
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", upgrade.DirReleases(root),
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz"),
+			upgrade.WithDestination(t.TempDir()))
+
+		// Assert
+		assert.ErrorContains(t, err, "unable to determine binary to install (files: bin/other)")
+	})
+
+	t.Run("error_binary_not_replaced", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dest, "repo", "dir"), cfs.RwxRxRxRx)) // binary can't be replaced by a non empty directory
+		require.NoError(t, os.WriteFile(filepath.Join(dest, "repo-server"), []byte("previous"), cfs.RwxRxRxRx))
+
+		// Act
+		err := runDir(t, getReleases, dest, upgrade.WithBinaryPath("repo", "*/bin/repo-*"))
+
+		// Assert
+		assert.ErrorContains(t, err, "safe move")
+		bytes, err := os.ReadFile(filepath.Join(dest, "repo-server"))
+		require.NoError(t, err)
+		assert.Equal(t, "previous", string(bytes)) // other binaries are only replaced once the binary is
+		assert.NoFileExists(t, filepath.Join(dest, "repo-server_"))
+	})
+
+	t.Run("success_recursive_default", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+
+		// Act
+		err := runDir(t, getReleases, dest)
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(filepath.Join(dest, "repo"))
+		require.NoError(t, err)
+		assert.Equal(t, "repo binary", string(bytes))
+		assert.NoFileExists(t, filepath.Join(dest, "repo-server"))
+	})
+
+	t.Run("success_nested_path", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+
+		// Act
+		err := runDir(t, getReleases, dest, upgrade.WithBinaryPath("{{ .Repo }}_{{ .Tag }}_{{ .GOOS }}_{{ .GOARCH }}/bin/{{ .Repo }}-server"))
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(filepath.Join(dest, "repo"))
+		require.NoError(t, err)
+		assert.Equal(t, "server binary", string(bytes))
+	})
+
+	t.Run("success_multiple_binaries", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+
+		// Act
+		err := runDir(t, getReleases, dest, upgrade.WithBinaryPath("repo", "*/bin/repo-*"))
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(filepath.Join(dest, "repo"))
+		require.NoError(t, err)
+		assert.Equal(t, "repo binary", string(bytes))
+		bytes, err = os.ReadFile(filepath.Join(dest, "repo-server"))
+		require.NoError(t, err)
+		assert.Equal(t, "server binary", string(bytes))
+		assert.NoFileExists(t, filepath.Join(dest, "LICENSE"))
+	})
+}
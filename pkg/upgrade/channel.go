@@ -0,0 +1,59 @@
+package upgrade
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// ChannelStable is the channel of releases which aren't prereleases.
+	ChannelStable = "stable"
+
+	// ChannelRC is the channel of release candidates (e.g. v1.0.0-rc.1, v1.0.0-pre.1, v1.0.0-preview
+	// or releases flagged as prerelease by their source without any prerelease in their tag).
+	ChannelRC = "rc"
+
+	// ChannelBeta is the channel of beta prereleases (e.g. v1.0.0-beta.1).
+	ChannelBeta = "beta"
+
+	// ChannelAlpha is the channel of alpha prereleases (e.g. v1.0.0-alpha.1).
+	ChannelAlpha = "alpha"
+
+	// ChannelNightly is the channel of nightly prereleases (e.g. v1.0.0-nightly.20240601) and any other unknown prerelease kind.
+	ChannelNightly = "nightly"
+)
+
+// _channels is the list of all channels ordered from the most stable one to the least stable one.
+var _channels = []string{ChannelStable, ChannelRC, ChannelBeta, ChannelAlpha, ChannelNightly}
+
+// channelOf returns the channel of the input release given its semver version (normalized tag name).
+//
+// A release without any semver prerelease but flagged as a prerelease by its source (see Release.Prerelease) is in ChannelRC.
+func channelOf(release *Release, version string) string {
+	s := _wordRegexp.FindAllString(semver.Prerelease(version), -1)
+	if len(s) == 0 {
+		if release.Prerelease {
+			return ChannelRC
+		}
+		return ChannelStable
+	}
+
+	// retrieve only the first word (see _wordRegexp)
+	switch strings.ToLower(s[0]) {
+	case "rc", "pre", "preview":
+		return ChannelRC
+	case "beta":
+		return ChannelBeta
+	case "alpha":
+		return ChannelAlpha
+	default:
+		return ChannelNightly
+	}
+}
+
+// channelRank returns the stability rank of the input channel (0 being the most stable one).
+func channelRank(channel string) int {
+	return slices.Index(_channels, channel)
+}
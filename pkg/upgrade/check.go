@@ -0,0 +1,61 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/mod/semver"
+)
+
+// CheckResult represents the result of Check.
+type CheckResult struct {
+	// AssetName is the name of the release asset matching WithAssetTemplate (empty with WithGoInstall or when no asset matches).
+	AssetName string
+
+	// AssetURL is the download URL of AssetName.
+	AssetURL string
+
+	// Latest is the tag of the release matching input options.
+	Latest string
+
+	// Newer is true when Latest is newer than the current version (see semver.Compare).
+	Newer bool
+
+	// Prerelease is true when Latest is a prerelease.
+	Prerelease bool
+}
+
+// Check reads all releases from the provided GetReleases function
+// and searches the appropriate release depending on input filters (major, minor, prerelease), exactly like Run,
+// but without downloading nor installing anything.
+//
+// It's useful to notify users that a new version is available (e.g. "a new version v2.3.0 is available").
+//
+// Installation options (WithDestination, WithTargetTemplate, WithKeepVersions, etc.) are ignored.
+// ErrNoNewVersion is returned when no release matches input options.
+func Check(ctx context.Context, repo, currentVersion string, getReleases GetReleases, opts ...RunOption) (CheckResult, error) {
+	ro, release, err := lookup(ctx, repo, getReleases, opts...)
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	result := CheckResult{
+		Latest:     release.TagName,
+		Newer:      semver.Compare(ro.normalize(release.TagName), ro.normalize(currentVersion)) > 0,
+		Prerelease: channelOf(release, ro.normalize(release.TagName)) != ChannelStable,
+	}
+	if ro.goInstall != "" {
+		return result, nil
+	}
+
+	asset, err := matchAsset(ro, release, newTemplateData(ro, repo, release))
+	if err != nil {
+		if errors.Is(err, errAssetNotFound) {
+			return result, nil // release may not be available for current platform
+		}
+		return CheckResult{}, err
+	}
+	result.AssetName = asset.Name
+	result.AssetURL = asset.DownloadURL
+	return result, nil
+}
@@ -0,0 +1,146 @@
+package upgrade_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-github/v63/github"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+
+	httpClient := cleanhttp.DefaultClient()
+	httpmock.ActivateNonDefault(httpClient)
+	t.Cleanup(httpmock.DeactivateAndReset)
+
+	getReleases := upgrade.GithubReleases("owner", "repo")
+	releasesURL := "https://api.github.com/repos/owner/repo/releases?page=1&per_page=100"
+	downloadURL := "http://example.com/asset/download/repo"
+	assetName := fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+
+	t.Run("error_missing_project_name", func(t *testing.T) {
+		// Act
+		_, err := upgrade.Check(ctx, "", "", nil)
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrNoProjectName)
+	})
+
+	t.Run("error_no_release", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{{TagName: toPtr("v1.0.0")}}))
+
+		// Act
+		_, err := upgrade.Check(ctx, "repo", "v1.0.0", getReleases,
+			upgrade.WithHTTPClient(httpClient),
+			upgrade.WithMajor("v2"))
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrNoNewVersion)
+	})
+
+	t.Run("success_newer", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{
+				{TagName: toPtr("v1.0.0")},
+				{
+					TagName: toPtr("v1.1.0-beta.1"),
+					Assets:  []*github.ReleaseAsset{{Name: &assetName, BrowserDownloadURL: &downloadURL}},
+				},
+			}))
+		expected := upgrade.CheckResult{
+			AssetName:  assetName,
+			AssetURL:   downloadURL,
+			Latest:     "v1.1.0-beta.1",
+			Newer:      true,
+			Prerelease: true,
+		}
+
+		// Act
+		result, err := upgrade.Check(ctx, "repo", "v1.0.0", getReleases,
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz"),
+			upgrade.WithHTTPClient(httpClient),
+			upgrade.WithPrereleases(true))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+		assert.Equal(t, 1, httpmock.GetTotalCallCount()) // ensure nothing was downloaded
+	})
+
+	t.Run("success_empty_constraint", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{{TagName: toPtr("v1.0.0")}, {TagName: toPtr("v2.0.0")}}))
+
+		// Act
+		result, err := upgrade.Check(ctx, "repo", "v1.0.0", getReleases,
+			upgrade.WithConstraint(""),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "v2.0.0", result.Latest)
+	})
+
+	t.Run("success_up_to_date", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{{TagName: toPtr("v1.0.0")}}))
+		expected := upgrade.CheckResult{Latest: "v1.0.0"} // no asset matching current platform
+
+		// Act
+		result, err := upgrade.Check(ctx, "repo", "v1.0.0", getReleases,
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz"),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("success_tag_prefix", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{
+				{
+					TagName: toPtr("mytool/v1.2.0"),
+					Assets:  []*github.ReleaseAsset{{Name: toPtr(fmt.Sprintf("mytool_1.2.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)), BrowserDownloadURL: &downloadURL}},
+				},
+				{TagName: toPtr("othertool/v2.0.0")},
+			}))
+		expected := upgrade.CheckResult{
+			AssetName: fmt.Sprintf("mytool_1.2.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH),
+			AssetURL:  downloadURL,
+			Latest:    "mytool/v1.2.0",
+			Newer:     true,
+		}
+
+		// Act
+		result, err := upgrade.Check(ctx, "mytool", "1.1.0", getReleases,
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .Version }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz"),
+			upgrade.WithHTTPClient(httpClient),
+			upgrade.WithTagPrefix("mytool/"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+}
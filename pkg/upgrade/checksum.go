@@ -0,0 +1,119 @@
+package upgrade
+
+import (
+	"encoding/hex"
+	"path"
+	"strings"
+)
+
+// checksumAlgorithms are the supported checksum algorithms (strongest first)
+// alongside their hexadecimal checksum length and whether they're weak (i.e. collisions can be forged).
+var checksumAlgorithms = []struct {
+	name   string
+	length int
+	weak   bool
+}{
+	{name: "sha512", length: 128},
+	{name: "sha256", length: 64},
+	{name: "sha1", length: 40, weak: true},
+	{name: "md5", length: 32, weak: true},
+}
+
+// findChecksum returns the checksums asset of assetName in release assets.
+//
+// When checksumName is given (see WithChecksumTemplate), only this asset is searched.
+// Otherwise, the following conventions are searched in order:
+//
+//   - checksums.txt
+//   - <anything>checksums.txt (e.g. repo_1.2.3_checksums.txt)
+//   - SHA512SUMS (or lowercase variant, with or without .txt extension)
+//   - <asset>.sha512 (per asset checksum file)
+//   - SHA256SUMS (or lowercase variant, with or without .txt extension)
+//   - <asset>.sha256 (per asset checksum file)
+func findChecksum(release *Release, assetName, checksumName string) (Asset, bool) {
+	if checksumName != "" {
+		return findAsset(release, checksumName)
+	}
+
+	if asset, ok := findAsset(release, "checksums.txt"); ok {
+		return asset, true
+	}
+	for _, asset := range release.Assets {
+		if strings.HasSuffix(strings.ToLower(asset.Name), "checksums.txt") {
+			return asset, true
+		}
+	}
+	for _, algorithm := range []string{"sha512", "sha256"} {
+		for _, asset := range release.Assets {
+			name := strings.TrimSuffix(strings.ToLower(asset.Name), ".txt")
+			if name == algorithm+"sums" {
+				return asset, true
+			}
+		}
+		if asset, ok := findAsset(release, assetName+"."+algorithm); ok {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// parseChecksum returns the checksum algorithm and value of assetName in the content of checksumsName file.
+//
+// Both GNU style ('<checksum>  <name>' or '<checksum> *<name>') and BSD style ('SHA256 (<name>) = <checksum>') are supported.
+// Per asset checksum files (e.g. repo_linux_amd64.tar.gz.sha256) can also only contain the checksum.
+//
+// The algorithm is read from checksumsName (e.g. SHA512SUMS or repo_linux_amd64.tar.gz.sha256)
+// or guessed from the checksum length (md5, sha1, sha256 or sha512).
+func parseChecksum(content []byte, checksumsName, assetName string) (string, string, bool) {
+	perAsset := strings.HasPrefix(checksumsName, assetName+".")
+
+	for _, line := range strings.Split(string(content), "\n") {
+		var checksum string
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 1 && perAsset: // checksum only
+			checksum = fields[0]
+		case len(fields) == 2 && (strings.TrimPrefix(fields[1], "*") == assetName || perAsset && path.Base(fields[1]) == assetName): // GNU style
+			checksum = fields[0]
+		case len(fields) == 4 && fields[1] == "("+assetName+")" && fields[2] == "=": // BSD style
+			checksum = fields[3]
+		default:
+			continue
+		}
+
+		if _, err := hex.DecodeString(checksum); err != nil {
+			continue
+		}
+		if algorithm, ok := checksumAlgorithm(checksumsName, checksum); ok {
+			return algorithm, strings.ToLower(checksum), true
+		}
+	}
+	return "", "", false
+}
+
+// weakChecksum returns true when the input algorithm (see checksumAlgorithms) can't be trusted to verify a signed checksums file.
+func weakChecksum(name string) bool {
+	for _, algorithm := range checksumAlgorithms {
+		if algorithm.name == name {
+			return algorithm.weak
+		}
+	}
+	return false
+}
+
+// checksumAlgorithm returns the algorithm of checksum, either from the checksums file name or from the checksum length.
+func checksumAlgorithm(checksumsName, checksum string) (string, bool) {
+	lower := strings.ToLower(checksumsName)
+	for _, algorithm := range checksumAlgorithms {
+		if strings.Contains(lower, algorithm.name) {
+			return algorithm.name, len(checksum) == algorithm.length
+		}
+	}
+
+	for _, algorithm := range checksumAlgorithms {
+		if len(checksum) == algorithm.length {
+			return algorithm.name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,169 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+)
+
+// companion represents a mapping between files in the downloaded asset and their installation directory (see WithCompanion).
+type companion struct {
+	dir     string
+	pattern string
+}
+
+// companionFile represents a companion file (or an additional binary, see WithBinaryPath) to install,
+// src being relative to the downloaded asset directory.
+type companionFile struct {
+	dest string
+	perm os.FileMode
+	src  string
+}
+
+// dataHome returns ${XDG_DATA_HOME} or ${HOME}/.local/share when it's not defined.
+func dataHome() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get user home dir: %w", err)
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// findCompanions returns all companion files to install from the downloaded asset directory (see WithCompanion).
+//
+// Companions are optional, as such patterns matching no file are ignored.
+func findCompanions(dir string, companions []companion, templateData map[string]any) ([]companionFile, error) {
+	if len(companions) == 0 {
+		return nil, nil
+	}
+
+	home, err := dataHome()
+	if err != nil {
+		return nil, fmt.Errorf("get data home: %w", err)
+	}
+	data := maps.Clone(templateData)
+	data["DataHome"] = home
+
+	files, err := walkFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []companionFile
+	for _, c := range companions {
+		pattern, err := getTemplateValue(c.pattern, data)
+		if err != nil {
+			return nil, fmt.Errorf("get companion path: %w", err)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid companion path '%s': %w", pattern, err)
+		}
+		destdir, err := getTemplateValue(c.dir, data)
+		if err != nil {
+			return nil, fmt.Errorf("get companion directory: %w", err)
+		}
+
+		for _, file := range files {
+			if !matchPath(pattern, file) {
+				continue
+			}
+			cf := companionFile{dest: filepath.Join(destdir, path.Base(file)), perm: cfs.RwRR, src: file}
+			if !slices.Contains(result, cf) {
+				result = append(result, cf)
+			}
+		}
+	}
+	return result, nil
+}
+
+// stageCompanions copies all companion files next to their destination (with '_' suffix),
+// for them to be moved in place alongside the binary with commitCompanions.
+//
+// Already staged files are removed in case of error.
+func stageCompanions(dir string, companions []companionFile) error {
+	for i, c := range companions {
+		err := os.MkdirAll(filepath.Dir(c.dest), cfs.RwxRxRxRx)
+		if err == nil {
+			err = cfs.CopyFile(filepath.Join(dir, filepath.FromSlash(c.src)), c.dest+"_", cfs.WithPerm(c.perm))
+		}
+		if err != nil {
+			return errors.Join(fmt.Errorf("stage companion '%s': %w", c.src, err), unstageCompanions(companions[:i+1]))
+		}
+	}
+	return nil
+}
+
+// unstageCompanions removes all staged companion files (see stageCompanions).
+func unstageCompanions(companions []companionFile) error {
+	var errs []error
+	for _, c := range companions {
+		if err := os.Remove(c.dest + "_"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("remove: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// backupCompanions keeps the files replaced by companions next to them with '.old' suffix (see oldPath)
+// to be restored with restoreCompanions and returns their backups (by replaced path).
+//
+// Already created backups are removed in case of error.
+func backupCompanions(companions []companionFile) (map[string]string, error) {
+	backups := map[string]string{}
+	for _, c := range companions {
+		if !cfs.Exists(c.dest) {
+			continue
+		}
+		old := oldPath(c.dest)
+		if err := cfs.CopyFile(c.dest, old, cfs.WithPerm(c.perm)); err != nil {
+			return nil, errors.Join(fmt.Errorf("backup companion '%s': %w", c.src, err), removeCompanionsBackups(backups))
+		}
+		backups[c.dest] = old
+	}
+	return backups, nil
+}
+
+// commitCompanions moves all staged companion files (see stageCompanions) in place and returns their paths.
+//
+// Already committed files are restored with their backups (see backupCompanions) in case of error.
+func commitCompanions(companions []companionFile, backups map[string]string) ([]string, error) {
+	paths := make([]string, 0, len(companions))
+	for i, c := range companions {
+		if err := os.Rename(c.dest+"_", c.dest); err != nil {
+			err = fmt.Errorf("move companion '%s': %w", c.src, err)
+			return nil, errors.Join(err, unstageCompanions(companions[i:]), restoreCompanions(paths, backups), removeCompanionsBackups(backups))
+		}
+		paths = append(paths, c.dest)
+	}
+	return paths, nil
+}
+
+// restoreCompanions moves back the backups of replaced files (see backupCompanions) in place
+// and removes the files that didn't exist before their installation.
+func restoreCompanions(paths []string, backups map[string]string) error {
+	var errs []error
+	for _, p := range paths {
+		if err := restore(backups[p], p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removeCompanionsBackups removes all backups of replaced files (see backupCompanions).
+func removeCompanionsBackups(backups map[string]string) error {
+	var errs []error
+	for _, old := range backups {
+		errs = append(errs, removeBackup(old))
+	}
+	return errors.Join(errs...)
+}
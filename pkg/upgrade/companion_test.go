@@ -0,0 +1,153 @@
+package upgrade_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+func TestCompanions(t *testing.T) {
+	ctx := context.Background()
+
+	root := t.TempDir()
+	asset := fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	writeArchive(t, filepath.Join(root, "v1.2.3", asset), map[string]string{
+		"repo":                  "repo binary",
+		"completions/repo.bash": "bash completion",
+		"completions/_repo":     "zsh completion",
+		"completions/repo.fish": "fish completion",
+		"man/repo.1":            "man page",
+		"docs/README.md":        "readme",
+	})
+	getReleases := upgrade.DirReleases(root)
+
+	t.Run("error_invalid_companion_option", func(t *testing.T) {
+		// Act
+		err := runDir(t, getReleases, t.TempDir(), upgrade.WithCompanion("", "dir"))
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrInvalidOptions)
+		assert.ErrorContains(t, err, "invalid companion '' to 'dir'")
+	})
+
+	t.Run("error_invalid_companion_path", func(t *testing.T) {
+		// Act
+		err := runDir(t, getReleases, t.TempDir(), upgrade.WithCompanion("docs/[*", t.TempDir()))
+
+		// Assert
+		assert.ErrorContains(t, err, "invalid companion path 'docs/[*'")
+	})
+
+	t.Run("error_invalid_companion_dir", func(t *testing.T) {
+		// Act
+		err := runDir(t, getReleases, t.TempDir(), upgrade.WithCompanion("docs/*", "{{ func }}"))
+
+		// Assert
+		assert.ErrorContains(t, err, "get companion directory")
+	})
+
+	t.Run("error_no_data_home", func(t *testing.T) {
+		if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+			t.Skip("home directory isn't retrieved from ${HOME}")
+		}
+
+		// Arrange
+		t.Setenv("XDG_DATA_HOME", "")
+		t.Setenv("HOME", "")
+		dest := t.TempDir()
+
+		// Act
+		err := runDir(t, getReleases, dest, upgrade.WithManPages("man/*.1"))
+
+		// Assert
+		assert.ErrorContains(t, err, "get data home")
+		assert.NoFileExists(t, filepath.Join(dest, "repo"))
+	})
+
+	t.Run("error_stage_companion", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(file, []byte("not a directory"), cfs.RwRR))
+
+		// Act
+		err := runDir(t, getReleases, dest,
+			upgrade.WithCompanion("docs/*", filepath.Join(dest, "docs")),
+			upgrade.WithCompanion("man/*", filepath.Join(file, "man")))
+
+		// Assert
+		assert.ErrorContains(t, err, "stage companion 'man/repo.1'")
+		assert.NoFileExists(t, filepath.Join(dest, "repo"))
+		assert.NoFileExists(t, filepath.Join(dest, "docs", "README.md_"))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Arrange
+		data := t.TempDir()
+		t.Setenv("XDG_DATA_HOME", data)
+		dest := t.TempDir()
+
+		// Act
+		err := runDir(t, getReleases, dest,
+			upgrade.WithCompanion("docs/*.md", "{{ .DataHome }}/doc/{{ .Repo }}"),
+			upgrade.WithCompanion("LICENSE", "{{ .DataHome }}/doc/{{ .Repo }}"), // optional
+			upgrade.WithCompletions("completions/*.bash", "completions/_{{ .Repo }}", "completions/*.fish"),
+			upgrade.WithManPages("man/*.1"),
+			upgrade.WithManPages("")) // ignored
+
+		// Assert
+		require.NoError(t, err)
+		for file, content := range map[string]string{
+			filepath.Join(dest, "repo"):                                        "repo binary",
+			filepath.Join(data, "bash-completion", "completions", "repo.bash"): "bash completion",
+			filepath.Join(data, "zsh", "site-functions", "_repo"):              "zsh completion",
+			filepath.Join(data, "fish", "vendor_completions.d", "repo.fish"):   "fish completion",
+			filepath.Join(data, "man", "man1", "repo.1"):                       "man page",
+			filepath.Join(data, "doc", "repo", "README.md"):                    "readme",
+		} {
+			bytes, err := os.ReadFile(file)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(bytes))
+		}
+		assert.NoFileExists(t, filepath.Join(data, "doc", "repo", "README.md_"))
+	})
+
+	t.Run("success_rollback", func(t *testing.T) {
+		// Arrange
+		data := t.TempDir()
+		t.Setenv("XDG_DATA_HOME", data)
+		dest, state := t.TempDir(), t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dest, "repo"), []byte("previous binary"), cfs.RwxRxRxRx))
+		require.NoError(t, os.MkdirAll(filepath.Join(data, "man", "man1"), cfs.RwxRxRxRx))
+		require.NoError(t, os.WriteFile(filepath.Join(data, "man", "man1", "repo.1"), []byte("previous man page"), cfs.RwRR))
+		require.NoError(t, runDir(t, getReleases, dest,
+			upgrade.WithCompanion("docs/*.md", "{{ .DataHome }}/doc/{{ .Repo }}"),
+			upgrade.WithManPages("man/*.1"),
+			upgrade.WithStateDir(state)))
+
+		// Act
+		_, err := upgrade.Rollback(ctx, "repo", upgrade.WithStateDir(state))
+
+		// Assert
+		require.NoError(t, err)
+		for file, content := range map[string]string{
+			filepath.Join(dest, "repo"):                  "previous binary",
+			filepath.Join(data, "man", "man1", "repo.1"): "previous man page",
+		} {
+			bytes, err := os.ReadFile(file)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(bytes))
+		}
+		assert.NoFileExists(t, filepath.Join(data, "man", "man1", "repo.1.old"))
+		assert.NoFileExists(t, filepath.Join(data, "doc", "repo", "README.md")) // didn't exist before
+	})
+}
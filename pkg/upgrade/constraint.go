@@ -0,0 +1,286 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Constraint represents a set of semver ranges a version must satisfy.
+//
+// It's a list of comparisons separated by commas (or spaces) which must all be satisfied,
+// and these lists can be separated by '||' in case only one of them must be satisfied, e.g.:
+//
+//	>=1.4.0, <2.0.0, !=1.7.0
+//	^1.4 || ^2.2
+//
+// Available comparisons are:
+//
+//   - '=1.4.2' or '1.4.2' (exactly v1.4.2), '=1.4' or '1.4' or '1.4.x' (any v1.4 version), '*' (any version)
+//   - '!=1.7.0' (anything but v1.7.0), '!=1.7' (anything but a v1.7 version)
+//   - '>1.4.2', '>=1.4.2', '<2.0.0', '<=2.0.0' ('>1.4' means from v1.5.0 and '<=1.4' means before v1.5.0)
+//   - '^1.4.2' (from v1.4.2 and before v2.0.0), '^0.4.2' (from v0.4.2 and before v0.5.0)
+//   - '~1.4.2' (from v1.4.2 and before v1.5.0), '~1' (any v1 version)
+//   - '1.4 - 1.8' (from v1.4.0 and before v1.9.0), '1.4.0 - 1.8.2' (from v1.4.0 until v1.8.2)
+//
+// Versions can be given with or without 'v' prefix. Prereleases are compared with semver rules,
+// but ranges upper bounds exclude their own prereleases (e.g. '^1.4' doesn't allow v2.0.0-beta.1).
+type Constraint struct {
+	groups [][]func(version string) bool
+	raw    string
+}
+
+// ParseConstraint parses the input constraint (see Constraint for the syntax).
+func ParseConstraint(constraint string) (*Constraint, error) {
+	c := &Constraint{raw: constraint}
+	for _, group := range strings.Split(constraint, "||") {
+		fields := strings.Fields(strings.ReplaceAll(group, ",", " "))
+		if len(fields) == 0 {
+			return nil, errors.New("empty constraint")
+		}
+
+		var checks []func(string) bool
+		for i := 0; i < len(fields); i++ {
+			// hyphen range (e.g. '1.4 - 1.8')
+			if i+2 < len(fields) && fields[i+1] == "-" {
+				check, err := parseHyphen(fields[i], fields[i+2])
+				if err != nil {
+					return nil, err
+				}
+				checks = append(checks, check)
+				i += 2
+				continue
+			}
+
+			// operator separated from its version (e.g. '>= 1.4')
+			field := fields[i]
+			if strings.Trim(field, "<>=!^~") == "" && i+1 < len(fields) {
+				field += fields[i+1]
+				i++
+			}
+
+			check, err := parseComparison(field)
+			if err != nil {
+				return nil, err
+			}
+			checks = append(checks, check)
+		}
+		c.groups = append(c.groups, checks)
+	}
+	return c, nil
+}
+
+// Check returns true when the input version satisfies the constraint.
+//
+// An invalid semver version never satisfies the constraint.
+func (c *Constraint) Check(version string) bool {
+	if !semver.IsValid(version) {
+		return false
+	}
+	for _, group := range c.groups {
+		ok := true
+		for _, check := range group {
+			if !check(version) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the constraint as given to ParseConstraint.
+func (c *Constraint) String() string {
+	if c == nil {
+		return ""
+	}
+	return c.raw
+}
+
+// partial represents a version which can be partially given (e.g. '1', '1.4', '1.x' or '*').
+type partial struct {
+	major, minor, patch int
+	n                   int    // number of given parts (0 with '*')
+	suffix              string // prerelease and build (e.g. '-beta.1+meta'), only with a full version
+}
+
+// parsePartial parses the input version (with or without 'v' prefix).
+func parsePartial(s string) (partial, error) {
+	raw := strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	core, suffix := raw, ""
+	if i := strings.IndexAny(raw, "-+"); i >= 0 {
+		core, suffix = raw[:i], raw[i:]
+	}
+
+	var p partial
+	wildcard := false
+	for i, part := range strings.Split(core, ".") {
+		if i > 2 {
+			return partial{}, fmt.Errorf("invalid version '%s'", s)
+		}
+		if part == "x" || part == "X" || part == "*" {
+			wildcard = true
+			continue
+		}
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 || wildcard { // wildcards can only be followed by other wildcards
+			return partial{}, fmt.Errorf("invalid version '%s'", s)
+		}
+		switch i {
+		case 0:
+			p.major = number
+		case 1:
+			p.minor = number
+		case 2:
+			p.patch = number
+		}
+		p.n++
+	}
+
+	if suffix != "" {
+		p.suffix = suffix
+		if p.n != 3 || !semver.IsValid(p.lower()) {
+			return partial{}, fmt.Errorf("invalid version '%s'", s)
+		}
+	}
+	return p, nil
+}
+
+// lower returns the lowest version of the partial version range (e.g. 'v1.4.0' for '1.4').
+func (p partial) lower() string {
+	return fmt.Sprintf("v%d.%d.%d%s", p.major, p.minor, p.patch, p.suffix)
+}
+
+// upper returns the exclusive upper bound of the partial version range (e.g. 'v1.5.0-0' for '1.4').
+//
+// It must not be called with a full version (or '*').
+func (p partial) upper() string {
+	if p.n == 1 {
+		return fmt.Sprintf("v%d.0.0-0", p.major+1)
+	}
+	return fmt.Sprintf("v%d.%d.0-0", p.major, p.minor+1)
+}
+
+// between returns a check ensuring a version is in [lower, upper) range. An empty upper means no upper bound.
+func between(lower, upper string) func(string) bool {
+	return func(v string) bool {
+		return semver.Compare(v, lower) >= 0 && (upper == "" || semver.Compare(v, upper) < 0)
+	}
+}
+
+// parseHyphen parses an hyphen range (e.g. '1.4 - 1.8').
+func parseHyphen(from, to string) (func(string) bool, error) {
+	lower, err := parsePartial(from)
+	if err != nil {
+		return nil, err
+	}
+	upper, err := parsePartial(to)
+	if err != nil {
+		return nil, err
+	}
+
+	switch upper.n {
+	case 0:
+		return between(lower.lower(), ""), nil
+	case 3:
+		return func(v string) bool {
+			return semver.Compare(v, lower.lower()) >= 0 && semver.Compare(v, upper.lower()) <= 0
+		}, nil
+	default:
+		return between(lower.lower(), upper.upper()), nil
+	}
+}
+
+// parseComparison parses a single comparison (e.g. '>=1.4.0', '^1.4', '!=1.7.0').
+func parseComparison(comparison string) (func(string) bool, error) {
+	var op string
+	for _, candidate := range []string{">=", "<=", "!=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(comparison, candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	p, err := parsePartial(strings.TrimPrefix(comparison, op))
+	if err != nil {
+		return nil, err
+	}
+	lower := p.lower()
+
+	switch op {
+	case "!=":
+		check := p.equal()
+		return func(v string) bool { return !check(v) }, nil
+	case ">":
+		if p.n == 0 {
+			return func(string) bool { return false }, nil
+		}
+		if p.n < 3 {
+			return between(p.upper(), ""), nil
+		}
+		return func(v string) bool { return semver.Compare(v, lower) > 0 }, nil
+	case ">=":
+		return between(lower, ""), nil
+	case "<":
+		return func(v string) bool { return semver.Compare(v, lower) < 0 }, nil
+	case "<=":
+		if p.n == 0 {
+			return func(string) bool { return true }, nil
+		}
+		if p.n < 3 {
+			return func(v string) bool { return semver.Compare(v, p.upper()) < 0 }, nil
+		}
+		return func(v string) bool { return semver.Compare(v, lower) <= 0 }, nil
+	case "^":
+		return p.caret(), nil
+	case "~":
+		return p.tilde(), nil
+	default:
+		return p.equal(), nil
+	}
+}
+
+// equal returns a check ensuring a version is the partial version (e.g. v1.4.2 for '1.4.2' or any v1.4 version for '1.4').
+func (p partial) equal() func(string) bool {
+	switch p.n {
+	case 0:
+		return func(string) bool { return true }
+	case 3:
+		return func(v string) bool { return semver.Compare(v, p.lower()) == 0 }
+	default:
+		return between(p.lower(), p.upper())
+	}
+}
+
+// caret returns a check ensuring a version doesn't modify the left-most non-zero part of the partial version.
+func (p partial) caret() func(string) bool {
+	switch {
+	case p.n == 0:
+		return func(string) bool { return true }
+	case p.major > 0 || p.n == 1:
+		return between(p.lower(), fmt.Sprintf("v%d.0.0-0", p.major+1))
+	case p.minor > 0 || p.n == 2:
+		return between(p.lower(), fmt.Sprintf("v0.%d.0-0", p.minor+1))
+	default:
+		return between(p.lower(), fmt.Sprintf("v0.0.%d-0", p.patch+1))
+	}
+}
+
+// tilde returns a check ensuring a version only modifies the patch part of the partial version
+// (or minor part in case only the major part is given).
+func (p partial) tilde() func(string) bool {
+	switch p.n {
+	case 0:
+		return func(string) bool { return true }
+	case 1:
+		return between(p.lower(), fmt.Sprintf("v%d.0.0-0", p.major+1))
+	default:
+		return between(p.lower(), fmt.Sprintf("v%d.%d.0-0", p.major, p.minor+1))
+	}
+}
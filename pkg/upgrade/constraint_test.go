@@ -0,0 +1,78 @@
+package upgrade_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+func TestParseConstraint(t *testing.T) {
+	for _, constraint := range []string{"", ">=1.4.0 ||", "1.2.3.4", ">=a", "^1.x.2-beta", "1.x.2", "1.2-beta"} {
+		t.Run("error_"+constraint, func(t *testing.T) {
+			// Act
+			_, err := upgrade.ParseConstraint(constraint)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestConstraintCheck(t *testing.T) {
+	cases := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{constraint: "1.4.2", version: "v1.4.2", expected: true},
+		{constraint: "=v1.4.2", version: "v1.4.3", expected: false},
+		{constraint: "1.4", version: "v1.4.9", expected: true},
+		{constraint: "1.4.x", version: "v1.5.0", expected: false},
+		{constraint: "*", version: "v0.0.1", expected: true},
+		{constraint: "!=1.7.0", version: "v1.7.0", expected: false},
+		{constraint: "!=1.7", version: "v1.7.3", expected: false},
+		{constraint: "!=1.7", version: "v1.8.0", expected: true},
+		{constraint: ">1.4.2", version: "v1.4.2", expected: false},
+		{constraint: ">1.4", version: "v1.4.9", expected: false},
+		{constraint: ">1.4", version: "v1.5.0", expected: true},
+		{constraint: ">= 1.4.0", version: "v1.4.0", expected: true},
+		{constraint: "<2.0.0", version: "v1.99.0", expected: true},
+		{constraint: "<=1.4", version: "v1.4.9", expected: true},
+		{constraint: "<=1.4", version: "v1.5.0", expected: false},
+		{constraint: "^1.4", version: "v1.9.0", expected: true},
+		{constraint: "^1.4", version: "v1.3.0", expected: false},
+		{constraint: "^1.4", version: "v2.0.0-beta.1", expected: false},
+		{constraint: "^0.4.2", version: "v0.4.9", expected: true},
+		{constraint: "^0.4.2", version: "v0.5.0", expected: false},
+		{constraint: "^0.0.3", version: "v0.0.4", expected: false},
+		{constraint: "~1.4.2", version: "v1.4.9", expected: true},
+		{constraint: "~1.4.2", version: "v1.5.0", expected: false},
+		{constraint: "~1", version: "v1.9.0", expected: true},
+		{constraint: "1.4 - 1.8", version: "v1.8.5", expected: true},
+		{constraint: "1.4 - 1.8", version: "v1.9.0", expected: false},
+		{constraint: "1.4.0 - 1.8.2", version: "v1.8.3", expected: false},
+		{constraint: ">=1.4.0, <2.0.0, !=1.7.0", version: "v1.7.0", expected: false},
+		{constraint: ">=1.4.0, <2.0.0, !=1.7.0", version: "v1.7.1", expected: true},
+		{constraint: ">=1.4.0 <2.0.0", version: "v2.0.0", expected: false},
+		{constraint: "^1.4 || ^3", version: "v3.2.0", expected: true},
+		{constraint: "^1.4 || ^3", version: "v2.2.0", expected: false},
+		{constraint: ">=1.0.0-beta.2", version: "v1.0.0-beta.10", expected: true},
+		{constraint: "*", version: "invalid", expected: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.constraint+"_"+tc.version, func(t *testing.T) {
+			// Arrange
+			constraint, err := upgrade.ParseConstraint(tc.constraint)
+			require.NoError(t, err)
+
+			// Act
+			ok := constraint.Check(tc.version)
+
+			// Assert
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirReleases returns a function listing all releases from a local directory (e.g. a shared mount on air-gapped machines).
+//
+// root must be laid out as '<root>/<tag>/<asset>', for instance:
+//
+//	/mnt/releases/repo/v1.0.0/repo_linux_amd64.tar.gz
+//	/mnt/releases/repo/v1.0.0/checksums.txt
+//	/mnt/releases/repo/v1.1.0/repo_linux_amd64.tar.gz
+//
+// root can also be given as a file:// URL. Assets download URLs are file:// URLs.
+func DirReleases(root string) func(ctx context.Context, httpClient *http.Client) ([]Release, error) {
+	return func(_ context.Context, _ *http.Client) ([]Release, error) {
+		abs, err := filepath.Abs(filepath.FromSlash(strings.TrimPrefix(root, "file://")))
+		if err != nil {
+			return nil, fmt.Errorf("absolute path: %w", err)
+		}
+
+		tags, err := os.ReadDir(abs)
+		if err != nil {
+			return nil, fmt.Errorf("read dir: %w", err)
+		}
+
+		releases := make([]Release, 0, len(tags))
+		for _, tag := range tags {
+			if !tag.IsDir() {
+				continue
+			}
+
+			assets, err := os.ReadDir(filepath.Join(abs, tag.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("read dir: %w", err)
+			}
+
+			release := Release{
+				Assets:  make([]Asset, 0, len(assets)),
+				TagName: tag.Name(),
+			}
+			for _, asset := range assets {
+				if asset.IsDir() {
+					continue
+				}
+				release.Assets = append(release.Assets, Asset{
+					DownloadURL: fileURL(filepath.Join(abs, tag.Name(), asset.Name())),
+					Name:        asset.Name(),
+				})
+			}
+			releases = append(releases, release)
+		}
+		return releases, nil
+	}
+}
+
+var _ GetReleases = DirReleases("/mnt/releases") // ensure interface is implemented
+
+// fileURL returns the file:// URL of the input absolute path.
+func fileURL(abs string) string {
+	p := filepath.ToSlash(abs)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // windows volume (e.g. C:/...)
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
@@ -0,0 +1,64 @@
+package upgrade_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+func TestDirReleases(t *testing.T) {
+	ctx := context.Background()
+
+	root := t.TempDir()
+	binary := fmt.Sprintf("repo_%s_%s", runtime.GOOS, runtime.GOARCH)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "v1.0.0", "subdir"), cfs.RwxRxRxRx))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "v1.0.0", binary), []byte("some binary"), cfs.RwxRxRxRx))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "README.md"), []byte("# Releases"), cfs.RwRR))
+
+	t.Run("error_read_dir", func(t *testing.T) {
+		// Act
+		_, err := upgrade.DirReleases(filepath.Join(root, "invalid"))(ctx, nil)
+
+		// Assert
+		assert.ErrorContains(t, err, "read dir")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Act
+		releases, err := upgrade.DirReleases(root)(ctx, nil)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, releases, 1)
+		assert.Equal(t, "v1.0.0", releases[0].TagName)
+		require.Len(t, releases[0].Assets, 1)
+		assert.Equal(t, binary, releases[0].Assets[0].Name)
+		assert.Regexp(t, "^file:///.+/v1.0.0/"+binary+"$", releases[0].Assets[0].DownloadURL)
+	})
+
+	t.Run("success_run", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", upgrade.DirReleases("file://"+filepath.ToSlash(root)),
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}"),
+			upgrade.WithDestination(dest),
+			upgrade.WithTargetTemplate("{{ .Repo }}"))
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(filepath.Join(dest, "repo"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("some binary"), bytes)
+	})
+}
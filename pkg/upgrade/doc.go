@@ -1,12 +1,50 @@
 /*
 The upgrade package provides the possibility to upgrade / install any package with various tunings:
 
-  - Specify the asset name to download (with templating)
+  - Specify the asset name to download (with templating, globs, regexps, GOOS / GOARCH aliases and fallbacks)
+  - Select the asset built for the host libc (gnu or musl) and x86-64 microarchitecture level (GOAMD64, with fallback to lower levels)
+  - Select the binaries to install inside multi-file archives (with templating and globs, searched recursively by default)
+  - Specify the checksums file name (with templating, various conventions are detected by default)
   - Installation destination
   - Specify the target binary name (with templating)
   - A specific major version
   - A specific minor version
-  - Include prereleases
+  - A semver constraint (e.g. '>=1.4.0, <2.0.0, !=1.7.0')
+  - An exact version (e.g. pinned in a lockfile, downgrades included)
+  - A tag prefix for repositories hosting multiple tools (e.g. 'mytool/v1.2.3')
+  - Include prereleases (all of them or only the most stable ones with a release channel: rc, beta, alpha, nightly)
+  - Keep older versions side by side (with a retention count)
+  - Install each version in its own directory with the target being a symbolic link to the active one (versioned layout)
+  - Replace the running executable wherever it's installed (self update, with a backup)
+  - Execute the installed binary to ensure it works (health check) and restore the previous one otherwise
+  - Rollback to the binary replaced by the last installation (see Rollback)
+  - Install companion files from the archive (shell completions, man pages, docs) and remove everything with Uninstall
+  - Verify the release checksums file signature (minisign, cosign or any Verifier)
+
+Releases can be retrieved from various sources:
+
+  - GitHub with GithubReleases
+  - GitLab with GitlabReleases
+  - Gitea (or Forgejo) with GiteaReleases
+  - Any HTTP server hosting a JSON (or YAML) index with HTTPIndexReleases
+  - An OCI registry where binaries are pushed as artifacts with OCIReleases
+  - A local directory (e.g. a shared mount on air-gapped machines) with DirReleases
+  - A go module proxy with GoProxyReleases (to be used with WithGoInstall to build the binary instead of downloading it)
+  - Any other source by implementing GetReleases
+
+Check can also be used to only report whether a new version is available (without downloading nor installing anything),
+for instance to display an "update available" message:
+
+	result, err := upgrade.Check(ctx, repo, currentVersion, upgrade.GithubReleases("owner", repo))
+	if err == nil && result.Newer {
+		fmt.Printf("a new version %s is available\n", result.Latest)
+	}
+
+Notify does the same in background with the last result cached for a given TTL (24 hours by default),
+avoiding to retrieve releases on every invocation:
+
+	notify := upgrade.Notify(ctx, repo, currentVersion, upgrade.GithubReleases("owner", repo))
+	defer notify(os.Stderr)
 
 Note than when using major or minor options, the current version will not be used.
 Why ? Because one could want to install an older version in case a breaking change was made by error
@@ -28,15 +66,19 @@ Example:
 
 		currentVersion := "v1.0.0" // currently installed version
 
-		err := upgrade.Run(ctx, repo, currentVersion,
+		_, err := upgrade.Run(ctx, repo, currentVersion,
 			upgrade.GithubReleases("owner", repo), // where to retrieve releases
 			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz"), // which asset should be downloaded (see associated doc)
 			upgrade.WithDestination("/tmp"), // installation destination
 			upgrade.WithKeepVersions(true), // whether to keep older versions or not
+			upgrade.WithRetention(3), // how many versions to keep when keeping older versions
 			upgrade.WithMajor(""), // whether to install a specific major version or not
 			upgrade.WithMinor(""), // whether to install a specific minor version or not
-			upgrade.WithPrerelease(false), // whether to include prereleases in filtering
+			upgrade.WithPrereleases(false), // whether to include prereleases in filtering
 		)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 */
 package upgrade
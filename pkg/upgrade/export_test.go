@@ -1,6 +1,9 @@
 package upgrade
 
 var (
+	Executable     = &_executable
+	GOAMD64        = &_goamd64
+	Libc           = &_libc
 	FindRelease    = findRelease
 	GetDownloadURL = getDownloadURL
 )
@@ -0,0 +1,91 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// giteaRelease represents a release returned by Gitea (or Forgejo) Releases API.
+type giteaRelease struct {
+	Assets []struct {
+		BrowserDownloadURL string `json:"browser_download_url"`
+		Name               string `json:"name"`
+	} `json:"assets"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	TagName     string    `json:"tag_name"`
+}
+
+// GiteaReleases returns a function listing all releases from a specific owner/repo in a gitea (or forgejo) instance.
+//
+// baseURL is the gitea instance URL (e.g. https://codeberg.org or https://gitea.example.com).
+//
+// Authentication is made with GITEA_TOKEN or FORGEJO_TOKEN environment variable.
+func GiteaReleases(baseURL, owner, repo string) func(ctx context.Context, httpClient *http.Client) ([]Release, error) {
+	toReleases := func(releases []giteaRelease) []Release {
+		result := make([]Release, 0, len(releases))
+		for _, r := range releases {
+			if r.TagName == "" {
+				continue
+			}
+			release := Release{
+				Assets:      make([]Asset, 0, len(r.Assets)),
+				Draft:       r.Draft,
+				Prerelease:  r.Prerelease,
+				PublishedAt: r.PublishedAt,
+				TagName:     r.TagName,
+			}
+
+			for _, asset := range r.Assets {
+				if asset.Name == "" || asset.BrowserDownloadURL == "" {
+					continue
+				}
+				release.Assets = append(release.Assets, Asset{DownloadURL: asset.BrowserDownloadURL, Name: asset.Name})
+			}
+
+			result = append(result, release)
+		}
+		return result
+	}
+
+	return func(ctx context.Context, httpClient *http.Client) ([]Release, error) {
+		header := http.Header{}
+		if token := os.Getenv("GITEA_TOKEN"); token != "" {
+			header.Set("Authorization", "token "+token)
+		} else if token := os.Getenv("FORGEJO_TOKEN"); token != "" {
+			header.Set("Authorization", "token "+token)
+		}
+
+		releasesURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/releases", strings.TrimSuffix(baseURL, "/"), url.PathEscape(owner), url.PathEscape(repo))
+
+		var all []Release
+		var count int
+		for page := 1; ; page++ {
+			var releases []giteaRelease
+			response, err := getJSON(ctx, httpClient, fmt.Sprintf("%s?limit=50&page=%d", releasesURL, page), header, &releases)
+			if err != nil {
+				return nil, fmt.Errorf("list releases: %w", err)
+			}
+			all = append(all, toReleases(releases)...)
+			count += len(releases)
+
+			// the instance may limit the page size below the asked one,
+			// as such the iteration stops on an empty page or when all releases (X-Total-Count) are retrieved
+			total, err := strconv.Atoi(response.Get("X-Total-Count"))
+			if len(releases) == 0 || (err == nil && count >= total) {
+				break
+			}
+		}
+
+		return all, nil
+	}
+}
+
+var _ GetReleases = GiteaReleases("https://gitea.com", "owner", "repo") // ensure interface is implemented
@@ -0,0 +1,95 @@
+package upgrade_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+func TestGiteaReleases(t *testing.T) {
+	ctx := context.Background()
+
+	// setup gitea mocking
+	httpClient := cleanhttp.DefaultClient()
+	httpmock.ActivateNonDefault(httpClient)
+	t.Cleanup(httpmock.DeactivateAndReset)
+
+	getReleases := upgrade.GiteaReleases("https://gitea.example.com", "owner", "repo")
+	releasesURL := "https://gitea.example.com/api/v1/repos/owner/repo/releases"
+
+	t.Run("error_pagination", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL+"?limit=50&page=1",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []map[string]any{{"tag_name": "v1.0.0"}}))
+		httpmock.RegisterResponder(http.MethodGet, releasesURL+"?limit=50&page=2",
+			httpmock.NewStringResponder(http.StatusInternalServerError, "error message"))
+
+		// Act
+		_, err := getReleases(ctx, httpClient)
+
+		// Assert
+		assert.ErrorContains(t, err, "limit=50&page=2: 500 error message") // error happened on page 2
+	})
+
+	t.Run("success_total_count", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		t.Setenv("FORGEJO_TOKEN", "token")
+		httpmock.RegisterResponder(http.MethodGet, releasesURL+"?limit=50&page=1",
+			func(req *http.Request) (*http.Response, error) {
+				if req.Header.Get("Authorization") != "token token" {
+					return httpmock.NewStringResponse(http.StatusUnauthorized, "unauthorized"), nil
+				}
+				return httpmock.NewJsonResponderOrPanic(http.StatusOK, []map[string]any{
+					{
+						"tag_name": "v1.0.0",
+						"assets": []map[string]any{
+							{"name": "repo_linux_amd64.tar.gz", "browser_download_url": "https://example.com/download"},
+							{"name": "repo_darwin_amd64.tar.gz"},
+						},
+					},
+				}).HeaderAdd(map[string][]string{"X-Total-Count": {"2"}})(req)
+			})
+		httpmock.RegisterResponder(http.MethodGet, releasesURL+"?limit=50&page=2",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []map[string]any{{"tag_name": "v1.0.1"}}).
+				HeaderAdd(map[string][]string{"X-Total-Count": {"2"}}))
+
+		// Act
+		releases, err := getReleases(ctx, httpClient)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []upgrade.Release{
+			{
+				TagName: "v1.0.0",
+				Assets:  []upgrade.Asset{{DownloadURL: "https://example.com/download", Name: "repo_linux_amd64.tar.gz"}},
+			},
+			{TagName: "v1.0.1", Assets: []upgrade.Asset{}},
+		}, releases)
+		assert.Equal(t, 2, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("success_empty_page", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL+"?limit=50&page=1",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []map[string]any{{"tag_name": "v1.0.0"}}))
+		httpmock.RegisterResponder(http.MethodGet, releasesURL+"?limit=50&page=2",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []map[string]any{}))
+
+		// Act
+		releases, err := getReleases(ctx, httpClient)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []upgrade.Release{{TagName: "v1.0.0", Assets: []upgrade.Asset{}}}, releases)
+	})
+}
@@ -20,8 +20,11 @@ func GithubReleases(owner, repo string) func(ctx context.Context, httpClient *ht
 				continue
 			}
 			release := Release{
-				Assets:  make([]Asset, 0, len(r.Assets)),
-				TagName: *r.TagName,
+				Assets:      make([]Asset, 0, len(r.Assets)),
+				Draft:       r.GetDraft(),
+				Prerelease:  r.GetPrerelease(),
+				PublishedAt: r.GetPublishedAt().Time,
+				TagName:     *r.TagName,
 			}
 
 			for _, asset := range r.Assets {
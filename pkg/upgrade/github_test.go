@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v63/github"
 	"github.com/hashicorp/go-cleanhttp"
@@ -62,4 +63,25 @@ func TestGithubReleases(t *testing.T) {
 			{TagName: "v1.0.1", Assets: []upgrade.Asset{}},
 		}, releases)
 	})
+
+	t.Run("success_flags", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		publishedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+		httpmock.RegisterResponder(http.MethodGet, "https://api.github.com/repos/owner/repo/releases",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{
+				{TagName: toPtr("v2.0.0"), Prerelease: toPtr(true), PublishedAt: &github.Timestamp{Time: publishedAt}},
+				{TagName: toPtr("v2.1.0"), Draft: toPtr(true)},
+			}))
+
+		// Act
+		releases, err := getReleases(ctx, httpClient)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []upgrade.Release{
+			{TagName: "v2.0.0", Assets: []upgrade.Asset{}, Prerelease: true, PublishedAt: publishedAt},
+			{TagName: "v2.1.0", Assets: []upgrade.Asset{}, Draft: true},
+		}, releases)
+	})
 }
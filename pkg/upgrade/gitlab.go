@@ -0,0 +1,98 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gitlabRelease represents a release returned by GitLab Releases API.
+type gitlabRelease struct {
+	Assets struct {
+		Links []struct {
+			DirectAssetURL string `json:"direct_asset_url"`
+			Name           string `json:"name"`
+			URL            string `json:"url"`
+		} `json:"links"`
+	} `json:"assets"`
+	ReleasedAt      time.Time `json:"released_at"`
+	TagName         string    `json:"tag_name"`
+	UpcomingRelease bool      `json:"upcoming_release"`
+}
+
+// GitlabReleases returns a function listing all releases from a specific project in a gitlab instance.
+//
+// baseURL is the gitlab instance URL (e.g. https://gitlab.com or https://gitlab.example.com)
+// and projectPath is the full path of the project (e.g. group/subgroup/project).
+//
+// Authentication is made with GITLAB_TOKEN environment variable (sent as PRIVATE-TOKEN)
+// or with CI_JOB_TOKEN environment variable (sent as JOB-TOKEN) when running inside gitlab CI.
+//
+// Release assets are the release links, with their direct asset URL when available.
+func GitlabReleases(baseURL, projectPath string) func(ctx context.Context, httpClient *http.Client) ([]Release, error) {
+	toReleases := func(releases []gitlabRelease) []Release {
+		result := make([]Release, 0, len(releases))
+		for _, r := range releases {
+			if r.TagName == "" {
+				continue
+			}
+			release := Release{
+				Assets:      make([]Asset, 0, len(r.Assets.Links)),
+				Draft:       r.UpcomingRelease, // not released yet
+				PublishedAt: r.ReleasedAt,
+				TagName:     r.TagName,
+			}
+
+			for _, link := range r.Assets.Links {
+				downloadURL := link.DirectAssetURL
+				if downloadURL == "" {
+					downloadURL = link.URL
+				}
+				if link.Name == "" || downloadURL == "" {
+					continue
+				}
+				release.Assets = append(release.Assets, Asset{DownloadURL: downloadURL, Name: link.Name})
+			}
+
+			result = append(result, release)
+		}
+		return result
+	}
+
+	return func(ctx context.Context, httpClient *http.Client) ([]Release, error) {
+		header := http.Header{}
+		if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+			header.Set("PRIVATE-TOKEN", token)
+		} else if token := os.Getenv("CI_JOB_TOKEN"); token != "" {
+			header.Set("JOB-TOKEN", token)
+		}
+
+		releasesURL := fmt.Sprintf("%s/api/v4/projects/%s/releases", strings.TrimSuffix(baseURL, "/"), url.PathEscape(projectPath))
+
+		var all []Release
+		page := "1"
+		for {
+			var releases []gitlabRelease
+			response, err := getJSON(ctx, httpClient, fmt.Sprintf("%s?page=%s&per_page=100", releasesURL, page), header, &releases)
+			if err != nil {
+				return nil, fmt.Errorf("list releases: %w", err)
+			}
+			all = append(all, toReleases(releases)...)
+
+			// X-Next-Page is empty on last page
+			if _, err := strconv.Atoi(response.Get("X-Next-Page")); err != nil {
+				break
+			}
+			page = response.Get("X-Next-Page")
+		}
+
+		return all, nil
+	}
+}
+
+var _ GetReleases = GitlabReleases("https://gitlab.com", "owner/repo") // ensure interface is implemented
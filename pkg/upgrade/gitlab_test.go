@@ -0,0 +1,85 @@
+package upgrade_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+func TestGitlabReleases(t *testing.T) {
+	ctx := context.Background()
+
+	// setup gitlab mocking
+	httpClient := cleanhttp.DefaultClient()
+	httpmock.ActivateNonDefault(httpClient)
+	t.Cleanup(httpmock.DeactivateAndReset)
+
+	getReleases := upgrade.GitlabReleases("https://gitlab.example.com/", "group/subgroup/repo")
+	releasesURL := "https://gitlab.example.com/api/v4/projects/group%2Fsubgroup%2Frepo/releases"
+
+	t.Run("error_pagination", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL+"?page=1&per_page=100",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []map[string]any{{"tag_name": "v1.0.0"}}).
+				HeaderAdd(map[string][]string{"X-Next-Page": {"2"}}))
+		httpmock.RegisterResponder(http.MethodGet, releasesURL+"?page=2&per_page=100",
+			httpmock.NewStringResponder(http.StatusInternalServerError, "error message"))
+
+		// Act
+		_, err := getReleases(ctx, httpClient)
+
+		// Assert
+		assert.ErrorContains(t, err, "page=2&per_page=100: 500 error message") // error happened on page 2
+	})
+
+	t.Run("success_multiple_pages", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		t.Setenv("GITLAB_TOKEN", "token")
+		httpmock.RegisterResponder(http.MethodGet, releasesURL+"?page=1&per_page=100",
+			func(req *http.Request) (*http.Response, error) {
+				if req.Header.Get("PRIVATE-TOKEN") != "token" {
+					return httpmock.NewStringResponse(http.StatusUnauthorized, "unauthorized"), nil
+				}
+				return httpmock.NewJsonResponderOrPanic(http.StatusOK, []map[string]any{
+					{
+						"tag_name": "v1.0.0",
+						"assets": map[string]any{
+							"links": []map[string]any{
+								{"name": "repo_linux_amd64.tar.gz", "url": "https://example.com/link", "direct_asset_url": "https://example.com/direct"},
+								{"name": "repo_darwin_amd64.tar.gz", "url": "https://example.com/link"},
+								{"name": "", "url": "https://example.com/link"},
+							},
+						},
+					},
+				}).HeaderAdd(map[string][]string{"X-Next-Page": {"2"}})(req)
+			})
+		httpmock.RegisterResponder(http.MethodGet, releasesURL+"?page=2&per_page=100",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []map[string]any{{"tag_name": "v1.0.1"}, {"tag_name": ""}}).
+				HeaderAdd(map[string][]string{"X-Next-Page": {""}}))
+
+		// Act
+		releases, err := getReleases(ctx, httpClient)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []upgrade.Release{
+			{
+				TagName: "v1.0.0",
+				Assets: []upgrade.Asset{
+					{DownloadURL: "https://example.com/direct", Name: "repo_linux_amd64.tar.gz"},
+					{DownloadURL: "https://example.com/link", Name: "repo_darwin_amd64.tar.gz"},
+				},
+			},
+			{TagName: "v1.0.1", Assets: []upgrade.Asset{}},
+		}, releases)
+	})
+}
@@ -0,0 +1,49 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+)
+
+// goInstall builds the provided package at the given version with 'go install' and moves the built binary into dest.
+func goInstall(ctx context.Context, pkg, version, dest string) error {
+	gocmd, err := exec.LookPath("go")
+	if err != nil {
+		return fmt.Errorf("look path: %w", err)
+	}
+
+	gobin, err := os.MkdirTemp("", "upgrade-")
+	if err != nil {
+		return fmt.Errorf("make temp dir: %w", err)
+	}
+	defer os.RemoveAll(gobin)
+
+	cmd := exec.CommandContext(ctx, gocmd, "install", pkg+"@"+version)
+	cmd.Env = append(os.Environ(), "GOBIN="+gobin)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("go install: %w: %s", err, string(out))
+	}
+
+	// move safely (as the current binary could be running) the newest version in place
+	if err := cfs.SafeMove(filepath.Join(gobin, goBinaryName(pkg)+binExt()), dest, cfs.WithPerm(cfs.RwxRxRxRx)); err != nil {
+		return fmt.Errorf("safe move: %w", err)
+	}
+	return nil
+}
+
+// goBinaryName returns the binary name built by 'go install' for the provided package path.
+//
+// It's the last element of the package path, or the one before in case the last one is a major version suffix (e.g. /v2).
+func goBinaryName(pkg string) string {
+	name := path.Base(pkg)
+	if dir := path.Dir(pkg); _majorRegexp.MatchString(name) && dir != "." {
+		return path.Base(dir)
+	}
+	return name
+}
@@ -0,0 +1,144 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// ErrGoProxyDisabled is the error returned by GoProxyReleases when no proxy can be used to list the module versions,
+// either because of GOPROXY=off or because the module is private (GOPRIVATE, GONOPROXY)
+// or because only direct mode is configured (listing from the version control system isn't supported).
+var ErrGoProxyDisabled = errors.New("no go proxy available for module")
+
+// errGoProxyNotFound is returned by goProxyList when the proxy answers with 404 or 410,
+// meaning that the next proxy (separated with ',') can be tried.
+var errGoProxyNotFound = errors.New("module not found")
+
+// GoProxyReleases returns a function listing all versions of a go module with GOPROXY protocol (<proxy>/<module>/@v/list).
+//
+// Proxies are read from GOPROXY environment variable (https://proxy.golang.org by default),
+// with the same fallback rules as the go command ('|' separated proxies are tried on any error
+// and ',' separated proxies only on not found errors). file:// proxies are supported too.
+//
+// Modules matching GONOPROXY (or GOPRIVATE when not set) patterns can't be listed since the version control system isn't supported.
+//
+// Returned releases don't have any asset, as such they should be used with WithGoInstall option.
+func GoProxyReleases(modulePath string) func(ctx context.Context, httpClient *http.Client) ([]Release, error) {
+	return func(ctx context.Context, httpClient *http.Client) ([]Release, error) {
+		escaped, err := module.EscapePath(modulePath)
+		if err != nil {
+			return nil, fmt.Errorf("escape module path: %w", err)
+		}
+
+		noproxy := os.Getenv("GONOPROXY")
+		if noproxy == "" {
+			noproxy = os.Getenv("GOPRIVATE")
+		}
+		if module.MatchPrefixPatterns(noproxy, modulePath) {
+			return nil, fmt.Errorf("%w: '%s' matches GONOPROXY or GOPRIVATE", ErrGoProxyDisabled, modulePath)
+		}
+
+		goproxy := os.Getenv("GOPROXY")
+		if goproxy == "" {
+			goproxy = "https://proxy.golang.org,direct"
+		}
+
+		var errs []error
+		for goproxy != "" {
+			// retrieve next proxy and whether fallback is made on any error or only not found errors
+			var proxy string
+			var fallbackOnErr bool
+			if i := strings.IndexAny(goproxy, ",|"); i >= 0 {
+				proxy, fallbackOnErr, goproxy = goproxy[:i], goproxy[i] == '|', goproxy[i+1:]
+			} else {
+				proxy, goproxy = goproxy, ""
+			}
+
+			switch proxy {
+			case "":
+				continue
+			case "off":
+				return nil, fmt.Errorf("%w: GOPROXY=off", ErrGoProxyDisabled)
+			case "direct":
+				errs = append(errs, fmt.Errorf("%w: direct mode isn't supported", ErrGoProxyDisabled))
+				continue
+			}
+
+			versions, err := goProxyList(ctx, httpClient, proxy, escaped)
+			if err == nil {
+				releases := make([]Release, 0, len(versions))
+				for _, version := range versions {
+					releases = append(releases, Release{TagName: version})
+				}
+				return releases, nil
+			}
+			errs = append(errs, err)
+			if !fallbackOnErr && !errors.Is(err, errGoProxyNotFound) {
+				break
+			}
+		}
+		return nil, fmt.Errorf("list versions: %w", errors.Join(errs...))
+	}
+}
+
+var _ GetReleases = GoProxyReleases("example.com/owner/repo") // ensure interface is implemented
+
+// goProxyList returns the list of versions available in proxy for the escaped module path.
+func goProxyList(ctx context.Context, httpClient *http.Client, proxy, escaped string) ([]string, error) {
+	listURL := strings.TrimSuffix(proxy, "/") + "/" + escaped + "/@v/list"
+
+	var body []byte
+	if strings.HasPrefix(listURL, "file://") {
+		u, err := url.Parse(listURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse url: %w", err)
+		}
+		if body, err = os.ReadFile(filepath.FromSlash(u.Path)); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("%w: %w", errGoProxyNotFound, err)
+			}
+			return nil, fmt.Errorf("read file: %w", err)
+		}
+	} else {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("new request: %w", err)
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("do request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if body, err = io.ReadAll(resp.Body); err != nil {
+			return nil, fmt.Errorf("read body: %w", err)
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusNotFound, http.StatusGone:
+			return nil, fmt.Errorf("%w: %s %s: %d", errGoProxyNotFound, http.MethodGet, listURL, resp.StatusCode)
+		default:
+			return nil, fmt.Errorf("%s %s: %d %s", http.MethodGet, listURL, resp.StatusCode, string(body))
+		}
+	}
+
+	var versions []string
+	for _, line := range strings.Split(string(body), "\n") {
+		// lines may contain other fields after the version (as specified in GOPROXY protocol)
+		fields := strings.Fields(line)
+		if len(fields) > 0 && semver.IsValid(fields[0]) {
+			versions = append(versions, fields[0])
+		}
+	}
+	return versions, nil
+}
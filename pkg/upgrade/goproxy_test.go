@@ -0,0 +1,141 @@
+package upgrade_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/zip"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+// fileProxy creates a GOPROXY directory serving the provided module version with given files
+// and returns the GOPROXY value to use it.
+func fileProxy(t *testing.T, mod module.Version, files map[string]string) string {
+	t.Helper()
+
+	srcdir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(srcdir, name), []byte(content), cfs.RwRR))
+	}
+
+	escaped, err := module.EscapePath(mod.Path)
+	require.NoError(t, err)
+	proxy := t.TempDir()
+	versions := filepath.Join(proxy, filepath.FromSlash(escaped), "@v")
+	require.NoError(t, os.MkdirAll(versions, cfs.RwxRxRxRx))
+	require.NoError(t, os.WriteFile(filepath.Join(versions, "list"), []byte(mod.Version+"\n"), cfs.RwRR))
+	require.NoError(t, os.WriteFile(filepath.Join(versions, mod.Version+".info"), []byte(`{"Version":"`+mod.Version+`"}`), cfs.RwRR))
+	require.NoError(t, os.WriteFile(filepath.Join(versions, mod.Version+".mod"), []byte(files["go.mod"]), cfs.RwRR))
+
+	file, err := os.Create(filepath.Join(versions, mod.Version+".zip"))
+	require.NoError(t, err)
+	defer file.Close()
+	require.NoError(t, zip.CreateFromDir(file, mod, srcdir))
+
+	return "file://" + filepath.ToSlash(proxy)
+}
+
+func TestGoProxyReleases(t *testing.T) {
+	ctx := context.Background()
+
+	// setup go proxy mocking
+	httpClient := cleanhttp.DefaultClient()
+	httpmock.ActivateNonDefault(httpClient)
+	t.Cleanup(httpmock.DeactivateAndReset)
+
+	getReleases := upgrade.GoProxyReleases("example.com/Owner/repo")
+
+	t.Run("error_private", func(t *testing.T) {
+		// Arrange
+		t.Setenv("GOPRIVATE", "example.com/Owner")
+
+		// Act
+		_, err := getReleases(ctx, httpClient)
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrGoProxyDisabled)
+	})
+
+	t.Run("error_off", func(t *testing.T) {
+		// Arrange
+		t.Setenv("GOPROXY", "off")
+
+		// Act
+		_, err := getReleases(ctx, httpClient)
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrGoProxyDisabled)
+	})
+
+	t.Run("error_no_fallback", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		t.Setenv("GOPROXY", "https://proxy1.example.com,https://proxy2.example.com")
+		httpmock.RegisterResponder(http.MethodGet, "https://proxy1.example.com/example.com/!owner/repo/@v/list",
+			httpmock.NewStringResponder(http.StatusInternalServerError, "error message"))
+
+		// Act
+		_, err := getReleases(ctx, httpClient)
+
+		// Assert
+		assert.ErrorContains(t, err, "500 error message")
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("error_direct", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		t.Setenv("GOPROXY", "https://proxy1.example.com,direct")
+		httpmock.RegisterResponder(http.MethodGet, "https://proxy1.example.com/example.com/!owner/repo/@v/list",
+			httpmock.NewStringResponder(http.StatusNotFound, "not found"))
+
+		// Act
+		_, err := getReleases(ctx, httpClient)
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrGoProxyDisabled)
+		assert.ErrorContains(t, err, "direct mode isn't supported")
+	})
+
+	t.Run("success_fallback", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		t.Setenv("GOPROXY", "https://proxy1.example.com|https://proxy2.example.com/,https://proxy3.example.com")
+		httpmock.RegisterResponder(http.MethodGet, "https://proxy1.example.com/example.com/!owner/repo/@v/list",
+			httpmock.NewStringResponder(http.StatusInternalServerError, "error message"))
+		httpmock.RegisterResponder(http.MethodGet, "https://proxy2.example.com/example.com/!owner/repo/@v/list",
+			httpmock.NewStringResponder(http.StatusGone, "gone"))
+		httpmock.RegisterResponder(http.MethodGet, "https://proxy3.example.com/example.com/!owner/repo/@v/list",
+			httpmock.NewStringResponder(http.StatusOK, "v1.0.0\nv1.1.0 2024-01-01T00:00:00Z\ninvalid\n"))
+
+		// Act
+		releases, err := getReleases(ctx, httpClient)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []upgrade.Release{{TagName: "v1.0.0"}, {TagName: "v1.1.0"}}, releases)
+	})
+
+	t.Run("success_file", func(t *testing.T) {
+		// Arrange
+		mod := module.Version{Path: "example.com/Owner/repo", Version: "v1.0.0"}
+		t.Setenv("GOPROXY", fileProxy(t, mod, map[string]string{"go.mod": "module example.com/Owner/repo\n"}))
+
+		// Act
+		releases, err := getReleases(ctx, httpClient)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []upgrade.Release{{TagName: "v1.0.0"}}, releases)
+	})
+}
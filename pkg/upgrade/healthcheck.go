@@ -0,0 +1,35 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrHealthCheck is the error returned by Run when the installed binary doesn't pass the health check (see WithHealthCheck).
+var ErrHealthCheck = errors.New("health check failed")
+
+// healthCheck represents the command executed on the installed binary to ensure it works.
+type healthCheck struct {
+	args    []string
+	timeout time.Duration
+}
+
+// run executes the binary bin with health check arguments and ensures its output contains the provided tag (with or without 'v' prefix).
+func (h healthCheck) run(ctx context.Context, bin, tag string) error {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	command := strings.Join(append([]string{bin}, h.args...), " ")
+	out, err := exec.CommandContext(ctx, bin, h.args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: '%s': %w: %s", ErrHealthCheck, command, err, strings.TrimSpace(string(out)))
+	}
+	if !strings.Contains(string(out), strings.TrimPrefix(tag, "v")) {
+		return fmt.Errorf("%w: '%s' output doesn't contain version '%s': %s", ErrHealthCheck, command, tag, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
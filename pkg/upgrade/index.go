@@ -0,0 +1,228 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+)
+
+// Index represents the document expected by HTTPIndexReleases, in JSON or YAML format.
+//
+// JSON example:
+//
+//	{
+//	  "releases": [
+//	    {
+//	      "tag_name": "v1.0.0",
+//	      "assets": [
+//	        { "name": "repo_linux_amd64.tar.gz", "url": "v1.0.0/repo_linux_amd64.tar.gz" },
+//	        { "name": "checksums.txt", "url": "https://cdn.example.com/repo/v1.0.0/checksums.txt" }
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// YAML example:
+//
+//	releases:
+//	  - tag_name: v1.0.0
+//	    assets:
+//	      - name: repo_linux_amd64.tar.gz
+//	        url: v1.0.0/repo_linux_amd64.tar.gz
+//
+// Assets URLs can be absolute or relative, in which case they're resolved against the index URL.
+type Index struct {
+	Releases []IndexRelease `json:"releases" yaml:"releases"`
+}
+
+// IndexRelease represents a release in an Index.
+//
+// Draft and prerelease flags as well as the publication date are optional.
+type IndexRelease struct {
+	Assets      []IndexAsset `json:"assets"                 yaml:"assets"`
+	Draft       bool         `json:"draft,omitempty"        yaml:"draft,omitempty"`
+	Prerelease  bool         `json:"prerelease,omitempty"   yaml:"prerelease,omitempty"`
+	PublishedAt time.Time    `json:"published_at,omitempty" yaml:"published_at,omitempty"`
+	TagName     string       `json:"tag_name"               yaml:"tag_name"`
+}
+
+// IndexAsset represents a release asset in an Index.
+type IndexAsset struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url"  yaml:"url"`
+}
+
+// IndexOption is the right function to tune HTTPIndexReleases with specific behaviors.
+type IndexOption func(*indexOptions)
+
+// WithIndexCache specifies a file where the last retrieved index and its ETag are stored.
+//
+// When given, the index is requested with If-None-Match header and the stored index is used
+// when the server answers with 304 Not Modified.
+//
+// By default, the ETag is only kept in memory (for the lifetime of the returned function).
+func WithIndexCache(file string) IndexOption {
+	return func(o *indexOptions) {
+		o.cacheFile = file
+	}
+}
+
+// indexOptions is the struct related to IndexOption function(s) defining all optional properties.
+type indexOptions struct {
+	cacheFile string
+}
+
+// indexCache represents the last retrieved index alongside its ETag.
+type indexCache struct {
+	Body        []byte `json:"body"`
+	ContentType string `json:"content_type"`
+	ETag        string `json:"etag"`
+}
+
+// HTTPIndexReleases returns a function listing all releases from an Index hosted at indexURL (e.g. https://example.com/repo/releases.json).
+//
+// The index is read as YAML when the response Content-Type or the URL extension is yaml (or yml), as JSON otherwise.
+//
+// Conditional requests are made with the index ETag (If-None-Match), see WithIndexCache to persist it between processes.
+func HTTPIndexReleases(indexURL string, opts ...IndexOption) func(ctx context.Context, httpClient *http.Client) ([]Release, error) {
+	var o indexOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	var mu sync.Mutex
+	var cache indexCache // in memory cache, read from cache file on each call when given
+
+	return func(ctx context.Context, httpClient *http.Client) ([]Release, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if o.cacheFile != "" {
+			cache = readIndexCache(o.cacheFile)
+		}
+
+		base, err := url.Parse(indexURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse index url: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("new request: %w", err)
+		}
+		if cache.ETag != "" && len(cache.Body) > 0 {
+			req.Header.Set("If-None-Match", cache.ETag)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("get index: %w", err)
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusNotModified:
+			// cached index is still up to date
+		case http.StatusOK:
+			bytes, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, fmt.Errorf("read index: %w", err)
+			}
+			cache = indexCache{Body: bytes, ContentType: resp.Header.Get("Content-Type"), ETag: resp.Header.Get("ETag")}
+			if o.cacheFile != "" {
+				if err := saveIndexCache(o.cacheFile, cache); err != nil {
+					return nil, fmt.Errorf("save index cache: %w", err)
+				}
+			}
+		default:
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			return nil, fmt.Errorf("get index: %s %s: %d %s", http.MethodGet, indexURL, resp.StatusCode, string(body))
+		}
+
+		var index Index
+		if strings.Contains(cache.ContentType, "yaml") || slices.Contains([]string{".yaml", ".yml"}, path.Ext(base.Path)) {
+			err = yaml.Unmarshal(cache.Body, &index)
+		} else {
+			err = json.Unmarshal(cache.Body, &index)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode index: %w", err)
+		}
+		return index.toReleases(base), nil
+	}
+}
+
+var _ GetReleases = HTTPIndexReleases("https://example.com/releases.json") // ensure interface is implemented
+
+// toReleases converts the index into releases while resolving relative assets URLs against base.
+func (i Index) toReleases(base *url.URL) []Release {
+	result := make([]Release, 0, len(i.Releases))
+	for _, r := range i.Releases {
+		if r.TagName == "" {
+			continue
+		}
+		release := Release{
+			Assets:      make([]Asset, 0, len(r.Assets)),
+			Draft:       r.Draft,
+			Prerelease:  r.Prerelease,
+			PublishedAt: r.PublishedAt,
+			TagName:     r.TagName,
+		}
+
+		for _, asset := range r.Assets {
+			if asset.Name == "" || asset.URL == "" {
+				continue
+			}
+			ref, err := url.Parse(asset.URL)
+			if err != nil {
+				continue
+			}
+			release.Assets = append(release.Assets, Asset{DownloadURL: base.ResolveReference(ref).String(), Name: asset.Name})
+		}
+
+		result = append(result, release)
+	}
+	return result
+}
+
+// readIndexCache reads the cache stored in file.
+//
+// A missing or invalid cache is the same as no cache.
+func readIndexCache(file string) indexCache {
+	var cache indexCache
+	if bytes, err := os.ReadFile(file); err == nil {
+		_ = json.Unmarshal(bytes, &cache)
+	}
+	return cache
+}
+
+// saveIndexCache writes the input cache into file.
+func saveIndexCache(file string, cache indexCache) error {
+	bytes, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), cfs.RwxRxRxRx); err != nil {
+		return fmt.Errorf("mkdir all: %w", err)
+	}
+	if err := os.WriteFile(file, bytes, cfs.RwRR); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package upgrade_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+func TestHTTPIndexReleases(t *testing.T) {
+	ctx := context.Background()
+
+	// setup index mocking
+	httpClient := cleanhttp.DefaultClient()
+	httpmock.ActivateNonDefault(httpClient)
+	t.Cleanup(httpmock.DeactivateAndReset)
+
+	index := upgrade.Index{
+		Releases: []upgrade.IndexRelease{
+			{
+				TagName: "v1.0.0",
+				Assets: []upgrade.IndexAsset{
+					{Name: "repo_linux_amd64.tar.gz", URL: "v1.0.0/repo_linux_amd64.tar.gz"},
+					{Name: "checksums.txt", URL: "https://cdn.example.com/v1.0.0/checksums.txt"},
+					{Name: "invalid"},
+				},
+			},
+			{TagName: ""},
+		},
+	}
+	expected := []upgrade.Release{
+		{
+			TagName: "v1.0.0",
+			Assets: []upgrade.Asset{
+				{DownloadURL: "https://example.com/repo/v1.0.0/repo_linux_amd64.tar.gz", Name: "repo_linux_amd64.tar.gz"},
+				{DownloadURL: "https://cdn.example.com/v1.0.0/checksums.txt", Name: "checksums.txt"},
+			},
+		},
+	}
+
+	t.Run("error_status", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, "https://example.com/repo/releases.json",
+			httpmock.NewStringResponder(http.StatusNotFound, "not found"))
+
+		// Act
+		_, err := upgrade.HTTPIndexReleases("https://example.com/repo/releases.json")(ctx, httpClient)
+
+		// Assert
+		assert.ErrorContains(t, err, "get index: GET https://example.com/repo/releases.json: 404 not found")
+	})
+
+	t.Run("error_decode", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, "https://example.com/repo/releases.json",
+			httpmock.NewStringResponder(http.StatusOK, "releases: []"))
+
+		// Act
+		_, err := upgrade.HTTPIndexReleases("https://example.com/repo/releases.json")(ctx, httpClient)
+
+		// Assert
+		assert.ErrorContains(t, err, "decode index")
+	})
+
+	t.Run("success_json", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, "https://example.com/repo/releases.json",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, index))
+
+		// Act
+		releases, err := upgrade.HTTPIndexReleases("https://example.com/repo/releases.json")(ctx, httpClient)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expected, releases)
+	})
+
+	t.Run("success_yaml", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, "https://example.com/repo/releases.yaml",
+			httpmock.NewStringResponder(http.StatusOK, `releases:
+  - tag_name: v1.0.0
+    assets:
+      - name: repo_linux_amd64.tar.gz
+        url: v1.0.0/repo_linux_amd64.tar.gz
+      - name: checksums.txt
+        url: https://cdn.example.com/v1.0.0/checksums.txt
+`))
+
+		// Act
+		releases, err := upgrade.HTTPIndexReleases("https://example.com/repo/releases.yaml")(ctx, httpClient)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expected, releases)
+	})
+
+	t.Run("success_flags", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, "https://example.com/repo/releases.yml",
+			httpmock.NewStringResponder(http.StatusOK, `releases:
+  - tag_name: v2.0.0
+    prerelease: true
+    published_at: 2024-06-01T12:00:00Z
+  - tag_name: v2.1.0
+    draft: true
+`))
+		expected := []upgrade.Release{
+			{TagName: "v2.0.0", Assets: []upgrade.Asset{}, Prerelease: true, PublishedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)},
+			{TagName: "v2.1.0", Assets: []upgrade.Asset{}, Draft: true},
+		}
+
+		// Act
+		releases, err := upgrade.HTTPIndexReleases("https://example.com/repo/releases.yml")(ctx, httpClient)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expected, releases)
+	})
+
+	t.Run("success_not_modified", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		var notModified int
+		httpmock.RegisterResponder(http.MethodGet, "https://example.com/repo/releases.json",
+			func(req *http.Request) (*http.Response, error) {
+				if req.Header.Get("If-None-Match") == `"etag"` {
+					notModified++
+					return httpmock.NewStringResponse(http.StatusNotModified, ""), nil
+				}
+				return httpmock.NewJsonResponderOrPanic(http.StatusOK, index).
+					HeaderSet(map[string][]string{"ETag": {`"etag"`}})(req)
+			})
+		cache := filepath.Join(t.TempDir(), "cache", "index.json")
+
+		getReleases := upgrade.HTTPIndexReleases("https://example.com/repo/releases.json", upgrade.WithIndexCache(cache)) // created before cache exists
+
+		// Act
+		_, err := upgrade.HTTPIndexReleases("https://example.com/repo/releases.json", upgrade.WithIndexCache(cache))(ctx, httpClient)
+		require.NoError(t, err)
+		releases, err := getReleases(ctx, httpClient)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, expected, releases)
+		assert.Equal(t, 1, notModified)
+	})
+}
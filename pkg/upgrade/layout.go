@@ -0,0 +1,168 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"golang.org/x/mod/semver"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+)
+
+// _linksFile is the file listing, in each version directory (see WithVersionedLayout), the files to link when the version is activated.
+const _linksFile = ".links.json"
+
+// activate points dest to the installed target when versions are kept side by side (see WithKeepVersions and WithVersionedLayout)
+// and prunes older versions (see WithRetention).
+//
+// The version the backup old points to (see backupLink) is never pruned for Rollback to restore it.
+func activate(ro runOptions, target, dest, versionDir, old string) error {
+	if !ro.keepVersions && versionDir == "" {
+		return nil
+	}
+
+	if err := link(target, dest); err != nil {
+		return fmt.Errorf("link version: %w", err)
+	}
+	backedUp := linkTarget(old)
+	if ro.keepVersions {
+		if err := pruneVersions(dest, target, backedUp, ro.retention); err != nil {
+			return fmt.Errorf("prune versions: %w", err)
+		}
+		return nil
+	}
+	if backedUp != "" {
+		backedUp = filepath.Dir(backedUp)
+	}
+	if err := pruneLayout(filepath.Dir(versionDir), versionDir, backedUp, ro.retention); err != nil {
+		return fmt.Errorf("prune versions: %w", err)
+	}
+	return nil
+}
+
+// pruneLayout removes the oldest version directories of dir (see WithVersionedLayout)
+// to only keep retention versions (current one included, see prune).
+func pruneLayout(dir, current, backedUp string, retention int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read dir: %w", err)
+	}
+
+	candidates := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() && semver.IsValid(entry.Name()) {
+			candidates[entry.Name()] = filepath.Join(dir, entry.Name())
+		}
+	}
+	return prune(candidates, current, backedUp, retention)
+}
+
+// layoutFiles moves the input companions into versionDir (under 'companions' with their path in the downloaded asset)
+// and returns them alongside the links to create on activation (link path to path relative to versionDir, see linkFiles),
+// other binaries (already in versionDir) being linked into destdir.
+func layoutFiles(destdir, versionDir string, others, companions []companionFile) ([]companionFile, map[string]string) {
+	links := make(map[string]string, len(others)+len(companions))
+	for _, o := range others {
+		links[filepath.Join(destdir, filepath.Base(o.dest))] = filepath.Base(o.dest)
+	}
+
+	result := make([]companionFile, 0, len(companions))
+	for _, c := range companions {
+		rel := filepath.Join("companions", filepath.FromSlash(c.src))
+		links[c.dest] = rel
+		cf := companionFile{dest: filepath.Join(versionDir, rel), perm: c.perm, src: c.src}
+		if !slices.Contains(result, cf) { // the same file can be linked into multiple directories
+			result = append(result, cf)
+		}
+	}
+	return result, links
+}
+
+// linkFiles links the other binaries and companions of versionDir (see layoutFiles) into their destination
+// and removes the ones of the version dest currently points to which aren't provided by versionDir.
+//
+// When backups are kept (see runOptions.keepBackups), replaced links are kept next to them with '.old' suffix (see backupLink)
+// to be restored with restoreCompanions, in which case their backups are returned too (by replaced path) alongside all linked (or removed) paths.
+func linkFiles(ro runOptions, dest, versionDir string) ([]string, map[string]string, error) {
+	links, err := readLinks(versionDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	paths := make([]string, 0, len(links))
+	for p := range links {
+		paths = append(paths, p)
+	}
+	if current := linkTarget(dest); current != "" && filepath.Dir(filepath.Dir(current)) == filepath.Dir(versionDir) {
+		previous, _ := readLinks(filepath.Dir(current)) // best effort, the version directory may be broken
+		for p := range previous {
+			if _, ok := links[p]; !ok && linkTarget(p) != "" {
+				paths = append(paths, p)
+			}
+		}
+	}
+	slices.Sort(paths)
+
+	files := make([]string, 0, len(paths))
+	backups := map[string]string{}
+	for _, p := range paths {
+		if ro.keepBackups() {
+			old, err := backupLink(p)
+			if err != nil {
+				return nil, nil, errors.Join(err, restoreCompanions(files, backups))
+			}
+			if old != "" {
+				backups[p] = old
+			}
+		}
+		files = append(files, p)
+
+		rel, ok := links[p]
+		if !ok {
+			err = os.Remove(p)
+		} else if err = os.MkdirAll(filepath.Dir(p), cfs.RwxRxRxRx); err == nil {
+			err = link(filepath.Join(versionDir, rel), p)
+		}
+		if err != nil {
+			return nil, nil, errors.Join(fmt.Errorf("link '%s': %w", p, err), restoreCompanions(files, backups))
+		}
+	}
+	return files, backups, nil
+}
+
+// readLinks reads the links to create when versionDir is activated (see layoutFiles).
+//
+// A version directory without any link file (nothing else than the binary was installed) gives no link.
+func readLinks(versionDir string) (map[string]string, error) {
+	bytes, err := os.ReadFile(filepath.Join(versionDir, _linksFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read links: %w", err)
+	}
+	var links map[string]string
+	if err := json.Unmarshal(bytes, &links); err != nil {
+		return nil, fmt.Errorf("unmarshal links: %w", err)
+	}
+	return links, nil
+}
+
+// saveLinks writes the links to create when versionDir is activated (see layoutFiles).
+func saveLinks(versionDir string, links map[string]string) error {
+	if len(links) == 0 {
+		return nil
+	}
+	bytes, err := json.Marshal(links)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(versionDir, _linksFile), bytes, cfs.RwRR); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
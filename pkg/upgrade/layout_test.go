@@ -0,0 +1,185 @@
+package upgrade_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+func TestVersionedLayoutWindows(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("versioned layout is only rejected on windows")
+	}
+
+	// Act
+	_, err := upgrade.Run(context.Background(), "repo", "", upgrade.DirReleases(t.TempDir()), upgrade.WithVersionedLayout(t.TempDir()))
+
+	// Assert
+	assert.ErrorIs(t, err, upgrade.ErrVersionedLayoutWindows)
+}
+
+func TestVersionedLayout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links aren't used on windows")
+	}
+	ctx := context.Background()
+
+	// releases writes v1.0.0 and v1.1.0 releases in a new directory and returns it
+	releases := func(t *testing.T) string {
+		t.Helper()
+		root := t.TempDir()
+		asset := fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+		for _, version := range []string{"v1.0.0", "v1.1.0"} {
+			writeArchive(t, filepath.Join(root, version, asset), map[string]string{
+				"repo":        "repo " + version,
+				"repo-server": "server " + version,
+				"man/repo.1":  "man " + version,
+			})
+		}
+		return root
+	}
+
+	// run installs the input version of repo with the versioned layout in root (companions being installed in '<root>/share')
+	run := func(t *testing.T, releases, root, dest, state, version string, opts ...upgrade.RunOption) error {
+		t.Helper()
+		t.Setenv("XDG_DATA_HOME", filepath.Join(root, "share"))
+		opts = append([]upgrade.RunOption{
+			upgrade.WithBinaryPath("repo", "repo-server"),
+			upgrade.WithManPages("man/*.1"),
+			upgrade.WithStateDir(state),
+			upgrade.WithVersion(version),
+			upgrade.WithVersionedLayout(root),
+		}, opts...)
+		return runDir(t, upgrade.DirReleases(releases), dest, opts...)
+	}
+
+	// assertActive asserts that dest binaries (and companions) are symbolic links to the input version in root
+	assertActive := func(t *testing.T, root, dest, version string) {
+		t.Helper()
+		target, err := filepath.EvalSymlinks(filepath.Join(dest, "repo"))
+		require.NoError(t, err)
+		expected, err := filepath.EvalSymlinks(filepath.Join(root, "repo", version, "repo"))
+		require.NoError(t, err)
+		assert.Equal(t, expected, target)
+		for file, content := range map[string]string{
+			filepath.Join(dest, "repo"):                           "repo " + version,
+			filepath.Join(dest, "repo-server"):                    "server " + version,
+			filepath.Join(root, "share", "man", "man1", "repo.1"): "man " + version,
+		} {
+			bytes, err := os.ReadFile(file)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(bytes))
+		}
+	}
+
+	t.Run("error_exclusive_options", func(t *testing.T) {
+		// Act
+		err := run(t, releases(t), t.TempDir(), t.TempDir(), t.TempDir(), "v1.0.0", upgrade.WithKeepVersions(true))
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrVersionedLayoutExclusive)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Arrange
+		src, root, dest, state := releases(t), t.TempDir(), t.TempDir(), t.TempDir()
+		require.NoError(t, run(t, src, root, dest, state, "v1.0.0"))
+
+		// Act
+		err := run(t, src, root, dest, state, "v1.1.0")
+
+		// Assert
+		require.NoError(t, err)
+		assertActive(t, root, dest, "v1.1.0")
+		assert.FileExists(t, filepath.Join(root, "repo", "v1.0.0", "repo"))
+		assert.FileExists(t, filepath.Join(root, "repo", "v1.1.0", "repo-server"))
+	})
+
+	t.Run("success_switch_without_download", func(t *testing.T) {
+		// Arrange
+		src, root, dest, state := releases(t), t.TempDir(), t.TempDir(), t.TempDir()
+		require.NoError(t, run(t, src, root, dest, state, "v1.0.0"))
+		require.NoError(t, run(t, src, root, dest, state, "v1.1.0"))
+		require.NoError(t, os.RemoveAll(filepath.Join(src, "v1.0.0")))
+		require.NoError(t, os.MkdirAll(filepath.Join(src, "v1.0.0"), cfs.RwxRxRxRx)) // release still exists but without any asset
+
+		// Act
+		err := run(t, src, root, dest, state, "v1.0.0")
+
+		// Assert
+		require.NoError(t, err)
+		assertActive(t, root, dest, "v1.0.0")
+	})
+
+	t.Run("success_rollback", func(t *testing.T) {
+		// Arrange
+		src, root, dest, state := releases(t), t.TempDir(), t.TempDir(), t.TempDir()
+		require.NoError(t, run(t, src, root, dest, state, "v1.0.0"))
+		require.NoError(t, run(t, src, root, dest, state, "v1.1.0"))
+
+		// Act
+		_, err := upgrade.Rollback(ctx, "repo", upgrade.WithStateDir(state))
+
+		// Assert
+		require.NoError(t, err)
+		assertActive(t, root, dest, "v1.0.0")
+		assert.FileExists(t, filepath.Join(root, "repo", "v1.1.0", "repo"))
+	})
+
+	t.Run("success_retention", func(t *testing.T) {
+		// Arrange
+		src, root, dest := releases(t), t.TempDir(), t.TempDir()
+		require.NoError(t, run(t, src, root, dest, "", "v1.0.0"))
+
+		// Act
+		err := run(t, src, root, dest, "", "v1.1.0", upgrade.WithRetention(1))
+
+		// Assert
+		require.NoError(t, err)
+		assertActive(t, root, dest, "v1.1.0")
+		assert.NoDirExists(t, filepath.Join(root, "repo", "v1.0.0"))
+		assert.NoFileExists(t, filepath.Join(dest, "repo-server.old")) // nothing is kept without state directory
+	})
+
+	t.Run("success_retention_rollback", func(t *testing.T) {
+		// Arrange
+		src, root, dest, state := releases(t), t.TempDir(), t.TempDir(), t.TempDir()
+		require.NoError(t, run(t, src, root, dest, state, "v1.0.0"))
+		require.NoError(t, run(t, src, root, dest, state, "v1.1.0", upgrade.WithRetention(1)))
+
+		// Act
+		_, err := upgrade.Rollback(ctx, "repo", upgrade.WithStateDir(state))
+
+		// Assert
+		require.NoError(t, err)
+		assertActive(t, root, dest, "v1.0.0") // backed up version isn't pruned
+	})
+
+	t.Run("success_uninstall", func(t *testing.T) {
+		// Arrange
+		src, root, dest, state := releases(t), t.TempDir(), t.TempDir(), t.TempDir()
+		require.NoError(t, run(t, src, root, dest, state, "v1.0.0"))
+		require.NoError(t, run(t, src, root, dest, state, "v1.1.0"))
+
+		// Act
+		err := upgrade.Uninstall(ctx, "repo", upgrade.WithStateDir(state))
+
+		// Assert
+		require.NoError(t, err)
+		assert.NoFileExists(t, filepath.Join(dest, "repo"))
+		assert.NoFileExists(t, filepath.Join(dest, "repo.old"))
+		assert.NoFileExists(t, filepath.Join(dest, "repo-server"))
+		assert.NoFileExists(t, filepath.Join(root, "share", "man", "man1", "repo.1"))
+		assert.NoFileExists(t, filepath.Join(root, "share", "man", "man1", "repo.1.old"))
+		assert.NoDirExists(t, filepath.Join(root, "repo"))
+	})
+}
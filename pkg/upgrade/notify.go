@@ -0,0 +1,223 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+)
+
+// NotifyOption is the right function to tune Notify with specific behaviors.
+type NotifyOption func(*notifyOptions)
+
+// WithNotifyRunOptions specifies the options given to Check when looking for a new release
+// (e.g. WithHTTPClient, WithMajor, WithPrereleases, etc.).
+func WithNotifyRunOptions(opts ...RunOption) NotifyOption {
+	return func(o *notifyOptions) {
+		o.runOptions = append(o.runOptions, opts...)
+	}
+}
+
+// WithNotifyStateFile specifies the file where the last check results are stored,
+// one result being stored per set of release filtering options (WithChannel, WithConstraint, WithMajor, WithMinor, WithPrereleases, WithTagPrefix and WithVersion).
+//
+// By default, it's ${XDG_CACHE_HOME}/<repo>/upgrade.json (see os.UserCacheDir).
+func WithNotifyStateFile(file string) NotifyOption {
+	return func(o *notifyOptions) {
+		o.stateFile = file
+	}
+}
+
+// WithNotifyTimeout specifies the maximum duration of the background check.
+//
+// By default, it's 2 seconds.
+func WithNotifyTimeout(timeout time.Duration) NotifyOption {
+	return func(o *notifyOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithNotifyTTL specifies for how long the last check result is considered up to date.
+// No new check is made until the stored result is older than ttl.
+//
+// By default, it's 24 hours.
+func WithNotifyTTL(ttl time.Duration) NotifyOption {
+	return func(o *notifyOptions) {
+		o.ttl = ttl
+	}
+}
+
+// notifyOptions is the struct related to NotifyOption function(s) defining all optional properties.
+type notifyOptions struct {
+	runOptions []RunOption
+	stateFile  string
+	timeout    time.Duration
+	ttl        time.Duration
+}
+
+// newNotifyOpt creates a new option struct with all input NotifyOption functions
+// while taking care of default values.
+func newNotifyOpt(repo string, opts ...NotifyOption) notifyOptions {
+	var o notifyOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	if o.stateFile == "" {
+		o.stateFile = filepath.Join(cacheDir(repo), "upgrade.json")
+	}
+	if o.timeout <= 0 {
+		o.timeout = 2 * time.Second
+	}
+	if o.ttl <= 0 {
+		o.ttl = 24 * time.Hour
+	}
+	return o
+}
+
+// cacheDir returns the default directory where upgrade states of repo are stored.
+func cacheDir(repo string) string {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		cache = os.TempDir()
+	}
+	return filepath.Join(cache, repo)
+}
+
+// notifyState represents the last check result made by Notify.
+type notifyState struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest,omitempty"`
+}
+
+// notifyStates represents the last check results made by Notify by release filtering options (see notifyKey).
+type notifyStates map[string]notifyState
+
+// notifyKey returns the identifier of the input release filtering options (e.g. 'channel=rc,major=v1'),
+// for Notify calls with different options not to share the same check result.
+func notifyKey(opts releaseOptions) string {
+	var parts []string
+	if opts.Channel != "" {
+		parts = append(parts, "channel="+opts.Channel)
+	}
+	if opts.Constraint != nil {
+		parts = append(parts, "constraint="+opts.Constraint.String())
+	}
+	if opts.Major != "" {
+		parts = append(parts, "major="+opts.Major)
+	}
+	if opts.Minor != "" {
+		parts = append(parts, "minor="+opts.Minor)
+	}
+	if opts.Prereleases {
+		parts = append(parts, "prereleases")
+	}
+	if opts.TagPrefix != "" {
+		parts = append(parts, "tag_prefix="+opts.TagPrefix)
+	}
+	if opts.Version != "" {
+		parts = append(parts, "version="+opts.Version)
+	}
+	if len(parts) == 0 {
+		return "default"
+	}
+	return strings.Join(parts, ",")
+}
+
+// Notify checks in background whether a newer release than currentVersion is available (see Check)
+// and returns a function to print a notice about it, typically called at the end of the process:
+//
+//	notify := upgrade.Notify(ctx, repo, currentVersion, upgrade.GithubReleases("owner", repo))
+//	defer notify(os.Stderr)
+//
+// The check result is stored in a state file (see WithNotifyStateFile) and reused until it's older than the TTL (see WithNotifyTTL),
+// as such, releases are retrieved at most once per TTL and not on every invocation.
+//
+// The returned function waits for the background check to end (at most the timeout given with WithNotifyTimeout)
+// and prints nothing when no newer version is known, when the check failed or when currentVersion isn't a valid semver version (e.g. development builds).
+func Notify(ctx context.Context, repo, currentVersion string, getReleases GetReleases, opts ...NotifyOption) func(w io.Writer) {
+	o := newNotifyOpt(repo, opts...)
+	ro, _ := newRunOpt(o.runOptions...) // invalid options are reported by Check, only release options are needed here
+	key := notifyKey(ro.releaseOptions)
+
+	states, err := readNotifyStates(o.stateFile)
+	if err != nil {
+		states = notifyStates{}
+	}
+	state, ok := states[key]
+	if ok && time.Since(state.CheckedAt) < o.ttl {
+		return func(w io.Writer) { state.print(w, repo, currentVersion, ro.releaseOptions) }
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ctx, cancel := context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+
+		result, err := Check(ctx, repo, currentVersion, getReleases, o.runOptions...)
+		if err != nil && !errors.Is(err, ErrNoNewVersion) {
+			return // don't save anything to check again on next invocation
+		}
+		state = notifyState{CheckedAt: time.Now(), Latest: result.Latest}
+		states[key] = state
+		_ = saveNotifyStates(o.stateFile, states) // nothing to do if the state can't be saved, it will be checked again next time
+	}()
+
+	return func(w io.Writer) {
+		<-done
+		state.print(w, repo, currentVersion, ro.releaseOptions)
+	}
+}
+
+// print writes a notice into w in case the latest known version is newer than currentVersion.
+func (s notifyState) print(w io.Writer, repo, currentVersion string, opts releaseOptions) {
+	current := opts.normalize(currentVersion)
+	if !semver.IsValid(current) || semver.Compare(opts.normalize(s.Latest), current) <= 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "A new version of %s is available: %s -> %s\n", repo, currentVersion, s.Latest)
+}
+
+// readNotifyStates reads the states stored in file.
+func readNotifyStates(file string) (notifyStates, error) {
+	bytes, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	var states notifyStates
+	if err := json.Unmarshal(bytes, &states); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	if states == nil {
+		return notifyStates{}, nil
+	}
+	return states, nil
+}
+
+// saveNotifyStates writes the input states into file.
+func saveNotifyStates(file string, states notifyStates) error {
+	bytes, err := json.Marshal(states)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), cfs.RwxRxRxRx); err != nil {
+		return fmt.Errorf("mkdir all: %w", err)
+	}
+	if err := os.WriteFile(file, bytes, cfs.RwRR); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
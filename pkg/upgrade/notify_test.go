@@ -0,0 +1,158 @@
+package upgrade_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v63/github"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+func TestNotify(t *testing.T) {
+	ctx := context.Background()
+
+	httpClient := cleanhttp.DefaultClient()
+	httpmock.ActivateNonDefault(httpClient)
+	t.Cleanup(httpmock.DeactivateAndReset)
+
+	getReleases := upgrade.GithubReleases("owner", "repo")
+	releasesURL := "https://api.github.com/repos/owner/repo/releases?page=1&per_page=100"
+	releases := []*github.RepositoryRelease{{TagName: toPtr("v1.0.0")}, {TagName: toPtr("v1.1.0")}}
+
+	t.Run("success_new_version", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL, httpmock.NewJsonResponderOrPanic(http.StatusOK, releases))
+		stateFile := filepath.Join(t.TempDir(), "repo", "upgrade.json")
+		var buf bytes.Buffer
+
+		// Act
+		upgrade.Notify(ctx, "repo", "v1.0.0", getReleases,
+			upgrade.WithNotifyRunOptions(upgrade.WithHTTPClient(httpClient)),
+			upgrade.WithNotifyStateFile(stateFile))(&buf)
+
+		// Assert
+		assert.Equal(t, "A new version of repo is available: v1.0.0 -> v1.1.0\n", buf.String())
+		assert.FileExists(t, stateFile)
+	})
+
+	t.Run("success_up_to_date", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL, httpmock.NewJsonResponderOrPanic(http.StatusOK, releases))
+		var buf bytes.Buffer
+
+		// Act
+		upgrade.Notify(ctx, "repo", "v1.1.0", getReleases,
+			upgrade.WithNotifyRunOptions(upgrade.WithHTTPClient(httpClient)),
+			upgrade.WithNotifyStateFile(filepath.Join(t.TempDir(), "upgrade.json")))(&buf)
+
+		// Assert
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("success_cached", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL, httpmock.NewJsonResponderOrPanic(http.StatusOK, releases))
+		stateFile := filepath.Join(t.TempDir(), "upgrade.json")
+		opts := []upgrade.NotifyOption{
+			upgrade.WithNotifyRunOptions(upgrade.WithHTTPClient(httpClient)),
+			upgrade.WithNotifyStateFile(stateFile),
+			upgrade.WithNotifyTTL(time.Hour),
+		}
+		upgrade.Notify(ctx, "repo", "v1.0.0", getReleases, opts...)(&bytes.Buffer{})
+		var buf bytes.Buffer
+
+		// Act
+		upgrade.Notify(ctx, "repo", "v1.0.0", getReleases, opts...)(&buf)
+
+		// Assert
+		assert.Equal(t, "A new version of repo is available: v1.0.0 -> v1.1.0\n", buf.String())
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("success_expired", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL, httpmock.NewJsonResponderOrPanic(http.StatusOK, releases))
+		stateFile := filepath.Join(t.TempDir(), "upgrade.json")
+		state := `{"default":{"checked_at":"2020-01-01T00:00:00Z","latest":"v1.0.0"}}`
+		require.NoError(t, os.WriteFile(stateFile, []byte(state), cfs.RwRR))
+		var buf bytes.Buffer
+
+		// Act
+		upgrade.Notify(ctx, "repo", "v1.0.0", getReleases,
+			upgrade.WithNotifyRunOptions(upgrade.WithHTTPClient(httpClient)),
+			upgrade.WithNotifyStateFile(stateFile))(&buf)
+
+		// Assert
+		assert.Equal(t, "A new version of repo is available: v1.0.0 -> v1.1.0\n", buf.String())
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("success_cached_by_options", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL, httpmock.NewJsonResponderOrPanic(http.StatusOK, releases))
+		stateFile := filepath.Join(t.TempDir(), "upgrade.json")
+		opts := []upgrade.NotifyOption{upgrade.WithNotifyStateFile(stateFile), upgrade.WithNotifyTTL(time.Hour)}
+		upgrade.Notify(ctx, "repo", "v1.0.0", getReleases,
+			append(opts, upgrade.WithNotifyRunOptions(upgrade.WithHTTPClient(httpClient), upgrade.WithMinor("v1.0")))...)(&bytes.Buffer{})
+		var buf bytes.Buffer
+
+		// Act
+		upgrade.Notify(ctx, "repo", "v1.0.0", getReleases, append(opts, upgrade.WithNotifyRunOptions(upgrade.WithHTTPClient(httpClient)))...)(&buf)
+
+		// Assert
+		assert.Equal(t, "A new version of repo is available: v1.0.0 -> v1.1.0\n", buf.String())
+		assert.Equal(t, 2, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("success_timeout", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, releases).Delay(time.Second))
+		stateFile := filepath.Join(t.TempDir(), "upgrade.json")
+		var buf bytes.Buffer
+		start := time.Now()
+
+		// Act
+		upgrade.Notify(ctx, "repo", "v1.0.0", getReleases,
+			upgrade.WithNotifyRunOptions(upgrade.WithHTTPClient(httpClient)),
+			upgrade.WithNotifyStateFile(stateFile),
+			upgrade.WithNotifyTimeout(10*time.Millisecond))(&buf)
+
+		// Assert
+		assert.Empty(t, buf.String())
+		assert.Less(t, time.Since(start), time.Second)
+		assert.NoFileExists(t, stateFile)
+	})
+
+	t.Run("success_development_build", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, releasesURL, httpmock.NewJsonResponderOrPanic(http.StatusOK, releases))
+		var buf bytes.Buffer
+
+		// Act
+		upgrade.Notify(ctx, "repo", "dev", getReleases,
+			upgrade.WithNotifyRunOptions(upgrade.WithHTTPClient(httpClient)),
+			upgrade.WithNotifyStateFile(filepath.Join(t.TempDir(), "upgrade.json")))(&buf)
+
+		// Assert
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,318 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+
+	getter "github.com/hashicorp/go-getter/v2"
+)
+
+const (
+	// ociTitleAnnotation is the annotation holding the file name of a layer (as pushed by oras for instance).
+	ociTitleAnnotation = "org.opencontainers.image.title"
+
+	// ociManifestAccept is the Accept header given when retrieving a manifest.
+	ociManifestAccept = "application/vnd.oci.image.manifest.v1+json, application/vnd.oci.image.index.v1+json, " +
+		"application/vnd.docker.distribution.manifest.v2+json, application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// ociDescriptor represents an OCI content descriptor (manifest in an index or layer in a manifest).
+type ociDescriptor struct {
+	Annotations map[string]string `json:"annotations"`
+	Digest      string            `json:"digest"`
+	MediaType   string            `json:"mediaType"`
+	Platform    *ociPlatform      `json:"platform,omitempty"`
+}
+
+// ociPlatform represents the platform of a manifest in an image index.
+type ociPlatform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+}
+
+// ociManifest represents either an OCI image manifest (with layers) or an OCI image index (with manifests).
+type ociManifest struct {
+	Layers    []ociDescriptor `json:"layers"`
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+// OCIReleases returns a function listing all releases from an OCI distribution-spec registry repository
+// where binaries are pushed as artifacts (e.g. with 'oras push registry.example.com/owner/repo:v1.0.0 repo_linux_amd64.tar.gz repo_darwin_arm64.tar.gz').
+//
+// registry is the registry host (e.g. ghcr.io or registry.example.com:5000),
+// it can be prefixed by a scheme (e.g. http://localhost:5000) otherwise https is used.
+//
+// Each semver tag is a release. Its assets are the layers annotated with 'org.opencontainers.image.title',
+// the title being the asset name (an image index is also supported, in which case its manifests layers are assets).
+// As such, titles are expected to hold the platform (e.g. repo_linux_amd64.tar.gz) to be matched with WithAssetTemplate,
+// unless the index manifests have a platform, in which case only the layers of the current platform manifests are assets.
+//
+// Only tags are listed by the returned function, the manifest of a release is retrieved once the release is picked by Run (or Check),
+// as such returned releases don't have any asset.
+//
+// Authentication is made with OCI_TOKEN environment variable (bearer token)
+// or OCI_USERNAME and OCI_PASSWORD environment variables (basic authentication or registry token exchange).
+// Anonymous registry token exchange is made otherwise.
+func OCIReleases(registry, repository string) func(ctx context.Context, httpClient *http.Client) ([]Release, error) {
+	if !strings.Contains(registry, "://") {
+		registry = "https://" + registry
+	}
+	base := fmt.Sprintf("%s/v2/%s", strings.TrimSuffix(registry, "/"), repository)
+
+	return func(ctx context.Context, httpClient *http.Client) ([]Release, error) {
+		client := &ociClient{httpClient: httpClient, token: os.Getenv("OCI_TOKEN")}
+		client.username, client.password = os.Getenv("OCI_USERNAME"), os.Getenv("OCI_PASSWORD")
+
+		tags, err := client.tags(ctx, base)
+		if err != nil {
+			return nil, fmt.Errorf("list tags: %w", err)
+		}
+
+		releases := make([]Release, 0, len(tags))
+		for _, tag := range tags {
+			releases = append(releases, Release{
+				TagName: tag,
+				resolve: func(ctx context.Context, httpClient *http.Client) ([]Asset, *http.Client, error) {
+					return client.assets(ctx, base, tag)
+				},
+			})
+		}
+		return releases, nil
+	}
+}
+
+var _ GetReleases = OCIReleases("registry.example.com", "owner/repo") // ensure interface is implemented
+
+// ociBlobURL returns the download URL of a blob.
+//
+// Since blobs are addressed by digest, the title is given to go-getter as the downloaded file name
+// or as the archive type when it's an archive (e.g. repo_linux_amd64.tar.gz).
+func ociBlobURL(base, digest, title string) string {
+	// find the longest matching archive extension (e.g. tar.gz instead of gz)
+	var archive string
+	for ext := range getter.Decompressors {
+		if strings.HasSuffix(title, "."+ext) && len(ext) > len(archive) {
+			archive = ext
+		}
+	}
+
+	query := url.Values{}
+	if archive != "" {
+		query.Set("archive", archive)
+	} else {
+		query.Set("filename", title)
+	}
+	return fmt.Sprintf("%s/blobs/%s?%s", base, digest, query.Encode())
+}
+
+// ociClient is a minimal OCI distribution-spec client handling registry authentication.
+type ociClient struct {
+	httpClient *http.Client
+	password   string
+	token      string
+	username   string
+}
+
+// assets returns the assets of the given tag (see OCIReleases) alongside an http client authenticating blobs downloads (see ociTransport).
+func (c *ociClient) assets(ctx context.Context, base, tag string) ([]Asset, *http.Client, error) {
+	layers, err := c.layers(ctx, base, tag)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get manifest '%s': %w", tag, err)
+	}
+
+	assets := make([]Asset, 0, len(layers))
+	for _, layer := range layers {
+		title := layer.Annotations[ociTitleAnnotation]
+		if title == "" || layer.Digest == "" {
+			continue
+		}
+		assets = append(assets, Asset{DownloadURL: ociBlobURL(base, layer.Digest, title), Name: title})
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse url: %w", err)
+	}
+	next := c.httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	httpClient := *c.httpClient
+	httpClient.Transport = &ociTransport{client: c, host: u.Host, next: next}
+	return assets, &httpClient, nil
+}
+
+// header returns the authentication header to use for registry requests.
+func (c *ociClient) header() http.Header {
+	header := http.Header{}
+	switch {
+	case c.token != "":
+		header.Set("Authorization", "Bearer "+c.token)
+	case c.username != "" || c.password != "":
+		header.Set("Authorization", "Basic "+basicAuth(c.username, c.password))
+	}
+	return header
+}
+
+// tags returns all tags of the repository by following pagination.
+func (c *ociClient) tags(ctx context.Context, base string) ([]string, error) {
+	var all []string
+	next := base + "/tags/list?n=100"
+	for next != "" {
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		response, err := c.get(ctx, next, "application/json", &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Tags...)
+
+		next, err = ociNextPage(next, response.Get("Link"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return all, nil
+}
+
+// layers returns all layers of the given reference manifest (or all layers of all manifests in case of an image index).
+//
+// Index manifests with a platform other than the current one (runtime.GOOS and runtime.GOARCH) are ignored.
+func (c *ociClient) layers(ctx context.Context, base, reference string) ([]ociDescriptor, error) {
+	var manifest ociManifest
+	if _, err := c.get(ctx, fmt.Sprintf("%s/manifests/%s", base, reference), ociManifestAccept, &manifest); err != nil {
+		return nil, err
+	}
+
+	layers := manifest.Layers
+	for _, descriptor := range manifest.Manifests {
+		if p := descriptor.Platform; p != nil && (p.OS != runtime.GOOS || p.Architecture != runtime.GOARCH) {
+			continue
+		}
+		var child ociManifest
+		if _, err := c.get(ctx, fmt.Sprintf("%s/manifests/%s", base, descriptor.Digest), ociManifestAccept, &child); err != nil {
+			return nil, err
+		}
+		layers = append(layers, child.Layers...)
+	}
+	return layers, nil
+}
+
+// get executes a GET request and decodes its JSON body into out.
+//
+// In case of an unauthorized response, registry token exchange is made (once) as specified by the WWW-Authenticate challenge.
+func (c *ociClient) get(ctx context.Context, rawURL, accept string, out any) (http.Header, error) {
+	header := c.header()
+	header.Set("Accept", accept)
+
+	response, err := getJSON(ctx, c.httpClient, rawURL, header, out)
+	var serr *statusError
+	if !errors.As(err, &serr) || serr.status != http.StatusUnauthorized || serr.header.Get("WWW-Authenticate") == "" {
+		return response, err
+	}
+
+	// retrieve a registry token and try again
+	token, err := c.exchange(ctx, serr.header.Get("WWW-Authenticate"))
+	if err != nil {
+		return nil, fmt.Errorf("token exchange: %w", err)
+	}
+	c.token = token
+
+	header = c.header()
+	header.Set("Accept", accept)
+	return getJSON(ctx, c.httpClient, rawURL, header, out)
+}
+
+// exchange retrieves a registry token as specified in WWW-Authenticate challenge
+// (e.g. Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:owner/repo:pull").
+func (c *ociClient) exchange(ctx context.Context, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported authentication challenge '%s'", challenge)
+	}
+
+	values := url.Values{}
+	var realm string
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		value = strings.Trim(value, `"`)
+		if key == "realm" {
+			realm = value
+			continue
+		}
+		values.Set(key, value)
+	}
+	if realm == "" {
+		return "", fmt.Errorf("missing realm in authentication challenge '%s'", challenge)
+	}
+
+	header := http.Header{}
+	if c.username != "" || c.password != "" {
+		header.Set("Authorization", "Basic "+basicAuth(c.username, c.password))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Token       string `json:"token"`
+	}
+	if _, err := getJSON(ctx, c.httpClient, realm+"?"+values.Encode(), header, &token); err != nil {
+		return "", err
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+// ociTransport is an http.RoundTripper adding registry authentication (see ociClient.header) to requests made to the registry host.
+//
+// Other hosts (e.g. a blob storage the registry redirects to) never receive registry credentials.
+type ociTransport struct {
+	client *ociClient
+	host   string
+	next   http.RoundTripper
+}
+
+var _ http.RoundTripper = (*ociTransport)(nil) // ensure interface is implemented
+
+// RoundTrip implements http.RoundTripper.
+func (t *ociTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host || req.Header.Get("Authorization") != "" {
+		return t.next.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	for key, values := range t.client.header() {
+		req.Header[key] = values
+	}
+	return t.next.RoundTrip(req)
+}
+
+// ociNextPage returns the next page URL from Link header (e.g. </v2/owner/repo/tags/list?last=v1.0.0&n=100>; rel="next").
+func ociNextPage(current, link string) (string, error) {
+	if link == "" {
+		return "", nil
+	}
+	ref, _, _ := strings.Cut(strings.TrimPrefix(link, "<"), ">")
+	u, err := url.Parse(current)
+	if err != nil {
+		return "", fmt.Errorf("parse url: %w", err)
+	}
+	next, err := u.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("parse link: %w", err)
+	}
+	return next.String(), nil
+}
+
+// basicAuth returns the base64 encoding of username and password for Basic authentication.
+func basicAuth(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
@@ -0,0 +1,185 @@
+package upgrade_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+// registry returns an in-process OCI registry stand-in serving owner/repo with token authentication
+// alongside the number of manifests requests it received.
+func registry(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var manifests atomic.Int32
+
+	binary := fmt.Sprintf("repo_%s_%s", runtime.GOOS, runtime.GOARCH)
+	var srv *httptest.Server
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(v))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("scope") != "repository:owner/repo:pull" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		writeJSON(w, map[string]string{"token": "token"})
+	})
+	mux.HandleFunc("/v2/owner/repo/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:owner/repo:pull"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if strings.HasPrefix(r.URL.Path, "/v2/owner/repo/manifests/") {
+			manifests.Add(1)
+		}
+		switch strings.TrimPrefix(r.URL.Path, "/v2/owner/repo/") {
+		case "tags/list":
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/owner/repo/tags/list?last=latest&n=100>; rel="next"`)
+				writeJSON(w, map[string]any{"tags": []string{"v1.0.0", "latest"}})
+				return
+			}
+			writeJSON(w, map[string]any{"tags": []string{"0.9.0", "v1.1.0"}})
+		case "manifests/v1.0.0":
+			writeJSON(w, map[string]any{"layers": []map[string]any{
+				{"digest": "sha256:binary", "annotations": map[string]string{"org.opencontainers.image.title": binary}},
+				{"digest": "sha256:untitled"},
+			}})
+		case "manifests/v1.1.0":
+			writeJSON(w, map[string]any{"manifests": []map[string]any{
+				{"digest": "sha256:other", "platform": map[string]string{"os": "plan9", "architecture": "mips"}},
+				{"digest": "sha256:current", "platform": map[string]string{"os": runtime.GOOS, "architecture": runtime.GOARCH}},
+			}})
+		case "manifests/sha256:other":
+			writeJSON(w, map[string]any{"layers": []map[string]any{
+				{"digest": "sha256:other-archive", "annotations": map[string]string{"org.opencontainers.image.title": "repo.tar.gz"}},
+			}})
+		case "manifests/sha256:current":
+			writeJSON(w, map[string]any{"layers": []map[string]any{
+				{"digest": "sha256:archive", "annotations": map[string]string{"org.opencontainers.image.title": "repo.tar.gz"}},
+			}})
+		case "manifests/0.9.0":
+			writeJSON(w, map[string]any{"layers": []map[string]any{
+				{"digest": "sha256:binary", "annotations": map[string]string{"org.opencontainers.image.title": binary}},
+			}})
+		case "blobs/sha256:binary":
+			_, _ = w.Write([]byte("some binary"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	srv = httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &manifests
+}
+
+func TestOCIReleases(t *testing.T) {
+	ctx := context.Background()
+
+	srv, manifests := registry(t)
+	getReleases := upgrade.OCIReleases(strings.TrimPrefix(srv.URL, "https://"), "owner/repo")
+
+	t.Run("error_list_tags", func(t *testing.T) {
+		// Act
+		_, err := upgrade.OCIReleases(srv.URL, "owner/invalid")(ctx, srv.Client())
+
+		// Assert
+		assert.ErrorContains(t, err, "list tags")
+		assert.ErrorContains(t, err, "404")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Arrange
+		manifests.Store(0)
+
+		// Act
+		releases, err := getReleases(ctx, srv.Client())
+
+		// Assert
+		require.NoError(t, err)
+		tags := make([]string, 0, len(releases))
+		for _, release := range releases {
+			assert.Empty(t, release.Assets)
+			tags = append(tags, release.TagName)
+		}
+		assert.Equal(t, []string{"v1.0.0", "latest", "0.9.0", "v1.1.0"}, tags) // tags are filtered when a release is picked
+		assert.Zero(t, manifests.Load())
+	})
+
+	t.Run("success_check_index", func(t *testing.T) {
+		// Arrange
+		manifests.Store(0)
+
+		// Act
+		result, err := upgrade.Check(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplate("repo.tar.gz"),
+			upgrade.WithHTTPClient(srv.Client()))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, upgrade.CheckResult{
+			AssetName: "repo.tar.gz",
+			AssetURL:  srv.URL + "/v2/owner/repo/blobs/sha256:archive?archive=tar.gz",
+			Latest:    "v1.1.0",
+			Newer:     true,
+		}, result)
+		assert.EqualValues(t, 2, manifests.Load()) // only v1.1.0 index and its current platform manifest
+	})
+
+	t.Run("success_run", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}"),
+			upgrade.WithVersion("v1.0.0"), // only v1.0.0 has the right asset for current platform
+			upgrade.WithDestination(dest),
+			upgrade.WithHTTPClient(srv.Client()),
+			upgrade.WithTargetTemplate("{{ .Repo }}"))
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(filepath.Join(dest, "repo"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("some binary"), bytes)
+	})
+
+	t.Run("success_run_unprefixed_tag", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+
+		// Act
+		version, err := upgrade.Run(ctx, "repo", "", getReleases,
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}"),
+			upgrade.WithVersion("0.9.0"),
+			upgrade.WithDestination(dest),
+			upgrade.WithHTTPClient(srv.Client()),
+			upgrade.WithTargetTemplate("{{ .Repo }}"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "0.9.0", version)
+		assert.FileExists(t, filepath.Join(dest, "repo"))
+	})
+}
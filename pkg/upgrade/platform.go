@@ -0,0 +1,65 @@
+package upgrade
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/cpu"
+)
+
+// _libc returns the host libc (overridable for testing purposes).
+var _libc = sync.OnceValue(libc)
+
+// _goamd64 returns the host x86-64 microarchitecture level (overridable for testing purposes).
+var _goamd64 = sync.OnceValue(goamd64)
+
+// libc returns the host libc on linux, 'musl' when the musl dynamic loader is present (e.g. alpine) or 'gnu' otherwise.
+//
+// It returns an empty string on other operating systems.
+func libc() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	if loaders, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(loaders) > 0 {
+		return "musl"
+	}
+	return "gnu"
+}
+
+// goamd64 returns the highest x86-64 microarchitecture level supported by the host CPU (see GOAMD64 in 'go help environment'),
+// e.g. 'v3' for a CPU supporting AVX2.
+//
+// It returns an empty string on other architectures.
+func goamd64() string {
+	if runtime.GOARCH != "amd64" {
+		return ""
+	}
+	x := cpu.X86
+	switch {
+	case x.HasAVX512F && x.HasAVX512BW && x.HasAVX512CD && x.HasAVX512DQ && x.HasAVX512VL:
+		return "v4"
+	case x.HasAVX && x.HasAVX2 && x.HasBMI1 && x.HasBMI2 && x.HasFMA && x.HasOSXSAVE:
+		return "v3"
+	case x.HasCX16 && x.HasPOPCNT && x.HasSSE3 && x.HasSSSE3 && x.HasSSE41 && x.HasSSE42:
+		return "v2"
+	default:
+		return "v1"
+	}
+}
+
+// goamd64Fallbacks returns the input x86-64 microarchitecture level followed by all lower ones (e.g. 'v3', 'v2', 'v1'),
+// since a binary built for a lower level runs on a CPU supporting a higher one.
+func goamd64Fallbacks(level string) []string {
+	n, err := strconv.Atoi(strings.TrimPrefix(level, "v"))
+	if err != nil || n < 1 {
+		return []string{level}
+	}
+	levels := make([]string, 0, n)
+	for ; n > 0; n-- {
+		levels = append(levels, "v"+strconv.Itoa(n))
+	}
+	return levels
+}
@@ -0,0 +1,99 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// getJSON executes a GET request on the provided url with the given headers
+// and decodes the response body into out.
+//
+// It returns the response headers to let callers handle pagination.
+func getJSON(ctx context.Context, httpClient *http.Client, url string, header http.Header, out any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, &statusError{body: string(body), header: resp.Header, status: resp.StatusCode, url: url}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return resp.Header, nil
+}
+
+// getBytes returns the content of the input asset,
+// either with an HTTP request or from local filesystem in case of a file:// URL.
+func getBytes(ctx context.Context, httpClient *http.Client, asset Asset) ([]byte, error) {
+	u, err := url.Parse(asset.DownloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+	if u.Scheme == "file" {
+		p := u.Path
+		if runtime.GOOS == "windows" {
+			p = strings.TrimPrefix(p, "/") // windows volume (e.g. /C:/...)
+		}
+		bytes, err := os.ReadFile(filepath.FromSlash(p))
+		if err != nil {
+			return nil, fmt.Errorf("read file: %w", err)
+		}
+		return bytes, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.DownloadURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{body: string(body), header: resp.Header, status: resp.StatusCode, url: asset.DownloadURL}
+	}
+	return body, nil
+}
+
+// statusError is the error returned by getJSON when the response status isn't 200 OK.
+type statusError struct {
+	body   string
+	header http.Header
+	status int
+	url    string
+}
+
+var _ error = (*statusError)(nil) // ensure interface is implemented
+
+// Error implements error.
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", http.MethodGet, e.url, e.status, e.body)
+}
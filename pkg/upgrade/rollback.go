@@ -0,0 +1,208 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+)
+
+var (
+	// ErrNoRollback is the error returned by Rollback when there's no previous installation to restore.
+	ErrNoRollback = errors.New("no previous installation to rollback to")
+
+	// ErrNoStateDir is the error returned by Rollback and Uninstall when no state directory is given (see WithStateDir).
+	ErrNoStateDir = errors.New("state directory must be given with WithStateDir")
+)
+
+// installRecord represents the last installation of a repo made by Run, stored in the state directory (see WithStateDir).
+type installRecord struct {
+	Backup          string            `json:"backup,omitempty"`
+	Backups         map[string]string `json:"backups,omitempty"` // backups of other replaced files (by path)
+	Dir             string            `json:"dir,omitempty"`     // version directory (see WithVersionedLayout)
+	Files           []string          `json:"files,omitempty"`   // other installed files (binaries and companions)
+	InstalledAt     time.Time         `json:"installed_at"`
+	Path            string            `json:"path"`
+	PreviousVersion string            `json:"previous_version,omitempty"`
+	Version         string            `json:"version"`
+	Versioned       bool              `json:"versioned,omitempty"` // versioned installations are kept next to path (see WithKeepVersions)
+}
+
+// Rollback restores the binary that was in place before the last Run (of the same repo) and returns its version
+// (the currentVersion given to Run, it may be empty).
+//
+// When a state directory is given (see WithStateDir, the same one must be given to Rollback),
+// Run keeps the replaced binary next to the installed one with '.old' suffix (e.g. 'repo.old' or 'repo.old.exe') and records the installation.
+// With versioned installations (see WithKeepVersions), the backup is a symbolic link to the replaced version.
+// Other replaced files (see WithBinaryPath and WithCompanion) are kept the same way and restored alongside the binary,
+// while the ones that didn't exist before are removed.
+//
+// ErrNoStateDir is returned when no state directory is given.
+// ErrNoRollback is returned when no installation was recorded, when nothing was replaced by the last installation
+// or when the backup was removed. Only the last installation can be rolled back.
+//
+// When WithHealthCheck is given, the restored binary is checked against its version (when known).
+// Other options are ignored.
+func Rollback(ctx context.Context, repo string, opts ...RunOption) (string, error) {
+	if repo == "" {
+		return "", ErrNoProjectName
+	}
+
+	ro, err := newRunOpt(opts...)
+	if err != nil {
+		return "", err
+	}
+	if ro.stateDir == "" {
+		return "", ErrNoStateDir
+	}
+
+	file := ro.installFile(repo)
+	record, err := readInstallRecord(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", ErrNoRollback
+		}
+		return "", fmt.Errorf("read install record: %w", err)
+	}
+	if record.Backup == "" || !cfs.Exists(record.Backup) {
+		return "", ErrNoRollback
+	}
+
+	if err := restore(record.Backup, record.Path); err != nil {
+		return "", err
+	}
+	if err := restoreCompanions(record.Files, record.Backups); err != nil {
+		return "", err
+	}
+	files := make([]string, 0, len(record.Backups))
+	for _, file := range record.Files {
+		if _, ok := record.Backups[file]; ok {
+			files = append(files, file)
+		}
+	}
+	// keep track of installed files for Uninstall, the backups being consumed
+	restored := installRecord{Dir: record.Dir, Files: files, InstalledAt: time.Now(), Path: record.Path, Version: record.PreviousVersion}
+	if err := saveInstallRecord(file, restored); err != nil {
+		return "", fmt.Errorf("save install record: %w", err)
+	}
+
+	if ro.healthCheck != nil && record.PreviousVersion != "" {
+		if err := ro.healthCheck.run(ctx, record.Path, ro.normalize(record.PreviousVersion)); err != nil {
+			return "", err
+		}
+	}
+	return record.PreviousVersion, nil
+}
+
+// backup preserves dest into 'dest.old' (or 'dest.old.exe' on windows) before it's replaced and returns the backup path.
+//
+// On windows, a running executable can't be overwritten but can be renamed, as such dest is moved and then copied back.
+//
+// An error wrapping fs.ErrPermission is returned in case dest directory isn't writable by the current user.
+func backup(dest string) (string, error) {
+	if !cfs.Exists(dest) {
+		return "", nil
+	}
+	old := oldPath(dest)
+
+	if err := writable(filepath.Dir(dest)); err != nil {
+		return "", fmt.Errorf("'%s' can't be replaced by current user, retry with elevated privileges (e.g. sudo): %w", dest, err)
+	}
+
+	if runtime.GOOS == "windows" {
+		if err := os.Rename(dest, old); err != nil {
+			return "", fmt.Errorf("move: %w", err)
+		}
+		if err := cfs.CopyFile(old, dest, cfs.WithPerm(cfs.RwxRxRxRx)); err != nil {
+			return "", fmt.Errorf("copy file: %w", err)
+		}
+		return old, nil
+	}
+	if err := cfs.CopyFile(dest, old, cfs.WithPerm(cfs.RwxRxRxRx)); err != nil {
+		return "", fmt.Errorf("copy file: %w", err)
+	}
+	return old, nil
+}
+
+// removeBackup removes the backup old (if any), e.g. when the installation failed while dest wasn't replaced.
+func removeBackup(old string) error {
+	if old == "" {
+		return nil
+	}
+	if err := os.Remove(old); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove backup: %w", err)
+	}
+	return nil
+}
+
+// restore moves back the backup old into dest.
+//
+// When there's no backup (dest didn't exist before installation), dest is removed.
+func restore(old, dest string) error {
+	if old == "" {
+		if err := os.Remove(dest); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("remove: %w", err)
+		}
+		return nil
+	}
+	if err := os.Rename(old, dest); err != nil {
+		return fmt.Errorf("restore backup: %w", err)
+	}
+	return nil
+}
+
+// oldPath returns the backup path of dest.
+//
+// For instance, '/usr/local/bin/repo' gives '/usr/local/bin/repo.old' and 'C:\Users\user\repo.exe' gives 'C:\Users\user\repo.old.exe'.
+func oldPath(dest string) string {
+	ext := filepath.Ext(dest)
+	if ext != binExt() {
+		ext = ""
+	}
+	return dest[:len(dest)-len(ext)] + ".old" + ext
+}
+
+// writable returns an error wrapping fs.ErrPermission in case a file can't be created in dir.
+func writable(dir string) error {
+	file, err := os.CreateTemp(dir, ".upgrade-*")
+	if err != nil {
+		return fmt.Errorf("create temp: %w", err)
+	}
+	_ = file.Close()
+	return os.Remove(file.Name())
+}
+
+// readInstallRecord reads the install record stored in file.
+func readInstallRecord(file string) (installRecord, error) {
+	bytes, err := os.ReadFile(file)
+	if err != nil {
+		return installRecord{}, fmt.Errorf("read file: %w", err)
+	}
+	var record installRecord
+	if err := json.Unmarshal(bytes, &record); err != nil {
+		return installRecord{}, fmt.Errorf("unmarshal: %w", err)
+	}
+	return record, nil
+}
+
+// saveInstallRecord writes the input record into file.
+func saveInstallRecord(file string, record installRecord) error {
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), cfs.RwxRxRxRx); err != nil {
+		return fmt.Errorf("mkdir all: %w", err)
+	}
+	if err := os.WriteFile(file, bytes, cfs.RwRR); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package upgrade_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-github/v63/github"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+func TestRollback(t *testing.T) {
+	ctx := context.Background()
+
+	httpClient := cleanhttp.DefaultClient()
+	httpmock.ActivateNonDefault(httpClient)
+	t.Cleanup(httpmock.DeactivateAndReset)
+
+	t.Run("error_missing_project_name", func(t *testing.T) {
+		// Act
+		_, err := upgrade.Rollback(ctx, "")
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrNoProjectName)
+	})
+
+	t.Run("error_no_state_dir", func(t *testing.T) {
+		// Act
+		_, err := upgrade.Rollback(ctx, "repo")
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrNoStateDir)
+	})
+
+	t.Run("error_no_installation", func(t *testing.T) {
+		// Act
+		_, err := upgrade.Rollback(ctx, "repo", upgrade.WithStateDir(t.TempDir()))
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrNoRollback)
+	})
+
+	t.Run("error_other_repo", func(t *testing.T) {
+		// Arrange
+		state := t.TempDir()
+		record := `{"backup":"/usr/local/bin/repo.old","path":"/usr/local/bin/repo","previous_version":"v0.9.0","version":"v1.0.0"}`
+		require.NoError(t, os.WriteFile(filepath.Join(state, "repo.install.json"), []byte(record), cfs.RwRR))
+
+		// Act
+		_, err := upgrade.Rollback(ctx, "other", upgrade.WithStateDir(state)) // state directory shared between repos
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrNoRollback)
+	})
+
+	t.Run("error_nothing_replaced", func(t *testing.T) {
+		// Arrange
+		state := t.TempDir()
+		record := `{"path":"/usr/local/bin/repo","previous_version":"v0.9.0","version":"v1.0.0"}`
+		require.NoError(t, os.WriteFile(filepath.Join(state, "repo.install.json"), []byte(record), cfs.RwRR))
+
+		// Act
+		_, err := upgrade.Rollback(ctx, "repo", upgrade.WithStateDir(state))
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrNoRollback)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		releasesURL := "https://api.github.com/repos/owner/repo/releases?page=1&per_page=100"
+		downloadURL := "http://example.com/asset/download/repo"
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{
+				{
+					TagName: toPtr("v1.0.0"),
+					Assets: []*github.ReleaseAsset{
+						{Name: toPtr(fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)), BrowserDownloadURL: &downloadURL},
+					},
+				},
+			}))
+		httpmock.RegisterResponder(http.MethodGet, downloadURL,
+			httpmock.NewStringResponder(http.StatusOK, "some text for a file"))
+
+		dest := t.TempDir()
+		state := t.TempDir()
+		ext := map[bool]string{true: ".exe"}[runtime.GOOS == "windows"]
+		require.NoError(t, os.WriteFile(filepath.Join(dest, "repo"+ext), []byte("previous"), cfs.RwxRxRxRx))
+
+		_, err := upgrade.Run(ctx, "repo", "v0.9.0", upgrade.GithubReleases("owner", "repo"),
+			upgrade.WithDestination(dest),
+			upgrade.WithHTTPClient(httpClient),
+			upgrade.WithStateDir(state))
+		require.NoError(t, err)
+
+		// Act
+		version, err := upgrade.Rollback(ctx, "repo", upgrade.WithStateDir(state))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "v0.9.0", version)
+		bytes, err := os.ReadFile(filepath.Join(dest, "repo"+ext))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("previous"), bytes)
+		assert.NoFileExists(t, filepath.Join(dest, "repo.old"+ext))
+
+		_, err = upgrade.Rollback(ctx, "repo", upgrade.WithStateDir(state))
+		assert.ErrorIs(t, err, upgrade.ErrNoRollback) // only the last installation can be rolled back
+	})
+
+	t.Run("success_keep_versions_retention", func(t *testing.T) {
+		// Arrange
+		ext := map[bool]string{true: ".exe"}[runtime.GOOS == "windows"]
+		root := t.TempDir()
+		for _, version := range []string{"v1.0.0", "v1.1.0"} {
+			writeArchive(t, filepath.Join(root, version, fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)), map[string]string{"repo" + ext: "repo " + version})
+		}
+		dest := t.TempDir()
+		state := t.TempDir()
+		run := func(version string) {
+			_, err := upgrade.Run(ctx, "repo", "", upgrade.DirReleases(root),
+				upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz"),
+				upgrade.WithDestination(dest),
+				upgrade.WithKeepVersions(true),
+				upgrade.WithRetention(1),
+				upgrade.WithStateDir(state),
+				upgrade.WithTargetTemplate("{{ .Repo }}{{ .BinExt }}"),
+				upgrade.WithVersion(version))
+			require.NoError(t, err)
+		}
+		run("v1.0.0")
+		run("v1.1.0")
+
+		// Act
+		_, err := upgrade.Rollback(ctx, "repo", upgrade.WithStateDir(state))
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(filepath.Join(dest, "repo"+ext))
+		require.NoError(t, err)
+		assert.Equal(t, "repo v1.0.0", string(bytes)) // backed up version isn't pruned
+	})
+}
@@ -5,10 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"slices"
+	"strings"
+	"time"
 
 	getter "github.com/hashicorp/go-getter/v2"
 	"golang.org/x/mod/semver"
@@ -32,11 +36,62 @@ var (
 	ErrAlreadyInstalled = errors.New("version already installed")
 )
 
+// VersionNotFoundError is the error returned by Run (or Check) when the version given with WithVersion doesn't exist.
+//
+// It wraps ErrNoNewVersion.
+type VersionNotFoundError struct {
+	// Nearby contains the closest existing versions (lower and greater) to help finding the right one.
+	Nearby  []string
+	Version string
+}
+
+var _ error = (*VersionNotFoundError)(nil) // ensure interface is implemented
+
+// Error implements error.
+func (e *VersionNotFoundError) Error() string {
+	if len(e.Nearby) == 0 {
+		return fmt.Sprintf("version '%s' not found", e.Version)
+	}
+	return fmt.Sprintf("version '%s' not found (nearby versions: %s)", e.Version, strings.Join(e.Nearby, ", "))
+}
+
+// Unwrap returns ErrNoNewVersion.
+func (*VersionNotFoundError) Unwrap() error {
+	return ErrNoNewVersion
+}
+
+// nearbyVersions returns the tags of at most the three lower and three greater versions around the input one.
+func nearbyVersions(releases []Release, opts releaseOptions, version string) []string {
+	candidates := candidates(releases, opts)
+	index, _ := slices.BinarySearchFunc(candidates, version, func(c candidate, version string) int { return semver.Compare(c.version, version) })
+
+	tags := make([]string, 0, 6)
+	for _, c := range candidates[max(0, index-3):min(len(candidates), index+3)] {
+		tags = append(tags, c.release.TagName)
+	}
+	return tags
+}
+
 // Release represents a release with its assets, its name
 // and other useful properties.
 type Release struct {
-	Assets  []Asset
+	Assets []Asset
+
+	// Draft is true when the release isn't published yet, a draft release is never installed.
+	Draft bool
+
+	// Prerelease is true when the release is flagged as a prerelease by its source,
+	// in which case it's considered as a prerelease even if its tag isn't a semver prerelease (e.g. v2.0.0).
+	Prerelease bool
+
+	// PublishedAt is the release publication date (zero when unknown).
+	PublishedAt time.Time
+
 	TagName string
+
+	// resolve retrieves the release assets when they're only listed once the release is picked (see OCIReleases),
+	// alongside the http client to download them with (e.g. with registry authentication).
+	resolve func(ctx context.Context, httpClient *http.Client) ([]Asset, *http.Client, error)
 }
 
 // Asset represents a release asset with its download URL and its name.
@@ -58,170 +113,498 @@ type GetReleases func(ctx context.Context, httpClient *http.Client) ([]Release,
 // and then installs it if found (or else does nothing).
 //
 // Installation, as provided in various functions docs, is made either in ${HOME}/.local/bin
-// or in provided destination directory with WithDestination option.
+// or in provided destination directory with WithDestination option (or in place of the running executable with WithSelfUpdate).
+//
+// When a state directory is given (see WithStateDir), the replaced binary (if any) is kept next to the installed one with '.old' suffix
+// and the installation is recorded to allow restoring it with Rollback (or removing it with Uninstall).
+//
+// See WithKeepVersions and WithVersionedLayout to keep multiple versions side by side.
 func Run(ctx context.Context, repo, currentVersion string, getReleases GetReleases, opts ...RunOption) (string, error) {
+	ro, release, err := lookup(ctx, repo, getReleases, opts...)
+	if err != nil {
+		return "", err
+	}
+	templateData := newTemplateData(ro, repo, release)
+
+	targetName, err := getTemplateValue(ro.targetTemplate, templateData)
+	if err != nil {
+		return "", fmt.Errorf("get target name: %w", err)
+	}
+	dest := filepath.Join(ro.destdir, targetName)
+	if ro.selfUpdate {
+		if dest, err = executable(); err != nil {
+			return "", err
+		}
+	}
+
+	target, versionDir := ro.installPath(repo, dest, release.TagName)
+
+	if ro.normalize(currentVersion) == ro.normalize(release.TagName) && cfs.Exists(dest) && cfs.Exists(target) {
+		return release.TagName, ErrAlreadyInstalled
+	}
+
+	// keep the replaced binary (and companions) as a backup for Rollback (see WithStateDir)
+	// or to restore it in case of failure (see WithSelfUpdate and WithHealthCheck)
+	var old string
+	if ro.keepBackups() {
+		backupFunc := backup
+		if ro.keepVersions || versionDir != "" {
+			backupFunc = backupLink
+		}
+		if old, err = backupFunc(dest); err != nil {
+			return "", err
+		}
+	}
+
+	// with versioned layout, an already installed version is only activated (e.g. when switching back to it)
+	extracted := versionDir != "" && cfs.Exists(target)
+	var files []string
+	var backups map[string]string
+	if !extracted {
+		if files, backups, err = install(ctx, ro, repo, release, templateData, target); err != nil {
+			return "", errors.Join(err, revert(old, target, dest))
+		}
+	}
+	if ro.healthCheck != nil {
+		if err := ro.healthCheck.run(ctx, target, ro.normalize(release.TagName)); err != nil {
+			if extracted { // already installed version (see WithVersionedLayout) is kept as is
+				return "", errors.Join(err, removeBackup(old))
+			}
+			return "", errors.Join(err, discard(ro, old, target, dest, versionDir), restoreCompanions(files, backups))
+		}
+	}
+
+	if versionDir != "" {
+		// other binaries and companions of the version are linked in place while dest still points to the previous version
+		if files, backups, err = linkFiles(ro, dest, versionDir); err != nil {
+			return "", errors.Join(err, removeBackup(old))
+		}
+	}
+
+	// backup is only kept for Rollback and with self update (companions backups only for Rollback)
+	if ro.stateDir == "" {
+		_ = removeCompanionsBackups(backups)
+		backups = nil
+	}
+	if ro.stateDir == "" && !ro.selfUpdate && old != "" {
+		_ = os.Remove(old)
+		old = ""
+	}
+
+	if err := activate(ro, target, dest, versionDir, old); err != nil {
+		return "", err
+	}
+
+	if ro.stateDir != "" {
+		record := installRecord{
+			Backup:          old,
+			Backups:         backups,
+			Dir:             versionDir,
+			Files:           files,
+			InstalledAt:     time.Now(),
+			Path:            dest,
+			PreviousVersion: currentVersion,
+			Version:         release.TagName,
+			Versioned:       ro.keepVersions,
+		}
+		_ = saveInstallRecord(ro.installFile(repo), record) // installation succeeded anyway, only Rollback would be unavailable
+	}
+	return release.TagName, nil
+}
+
+// installPath returns the path where the release must be installed (target) and its version directory (see WithVersionedLayout).
+//
+// Target is dest unless older versions must be kept, in which case it's a versioned file (see WithKeepVersions)
+// or a file in the version directory (see WithVersionedLayout).
+func (ro runOptions) installPath(repo, dest, tag string) (target, versionDir string) {
+	switch {
+	case ro.keepVersions:
+		return versionedPath(dest, ro.normalize(tag)), ""
+	case ro.layoutRoot != "":
+		versionDir = filepath.Join(ro.layoutRoot, repo, ro.normalize(tag))
+		return filepath.Join(versionDir, filepath.Base(dest)), versionDir
+	default:
+		return dest, ""
+	}
+}
+
+// revert restores the replaced binary (if it was backed up) after a failed installation.
+//
+// With versioned installations, dest isn't replaced by the installation, as such the backup is only removed.
+func revert(old, target, dest string) error {
+	switch {
+	case old == "":
+		return nil
+	case target != dest:
+		return removeBackup(old)
+	default:
+		return restore(old, dest)
+	}
+}
+
+// discard reverts the installation of target (after a failed health check).
+//
+// With versioned installations, target isn't linked yet, as such dest still points to the previous version
+// and only target is removed alongside the backup.
+// Otherwise, the previous binary is restored.
+func discard(ro runOptions, old, target, dest, versionDir string) error {
+	switch {
+	case ro.keepVersions:
+		return errors.Join(os.Remove(target), removeBackup(old))
+	case versionDir != "":
+		return errors.Join(os.RemoveAll(versionDir), removeBackup(old))
+	default:
+		return restore(old, dest)
+	}
+}
+
+// install builds (with WithGoInstall) or downloads the release asset into dest.
+//
+// It returns the paths of all other installed files (other binaries and companions, see WithBinaryPath and WithCompanion)
+// alongside the backups of the replaced ones (see backupCompanions).
+func install(ctx context.Context, ro runOptions, repo string, release *Release, templateData map[string]any, dest string) ([]string, map[string]string, error) {
+	if ro.goInstall != "" {
+		if err := goInstall(ctx, ro.goInstall, release.TagName, dest); err != nil {
+			return nil, nil, fmt.Errorf("build: %w", err)
+		}
+		return nil, nil, nil
+	}
+
+	asset, err := matchAsset(ro, release, templateData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var checksumName string
+	if ro.checksumTemplate != "" {
+		templateData["Asset"] = asset.Name
+		if checksumName, err = getTemplateValue(ro.checksumTemplate, templateData); err != nil {
+			return nil, nil, fmt.Errorf("get checksum name: %w", err)
+		}
+	}
+	patterns, err := getTemplateValues(ro.binaryPaths, templateData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get binary path: %w", err)
+	}
+
+	tmp, downloaded, err := download(ctx, ro, repo, release, asset.Name, checksumName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	binaries, err := findBinaries(tmp, patterns, []string{urlBase(downloaded.DownloadURL), asset.Name, repo + binExt()})
+	if err != nil {
+		return nil, nil, err
+	}
+	companions, err := findCompanions(tmp, ro.companions, templateData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// other binaries and companions are staged beforehand to be moved in place only once the binary is installed
+	others := otherBinaries(binaries, dest)
+	var links map[string]string
+	if ro.layoutRoot != "" {
+		// with versioned layout, all files are kept in the version directory and linked in place on activation
+		companions, links = layoutFiles(ro.destdir, filepath.Dir(dest), others, companions)
+	}
+	companions = append(others, companions...)
+	if err := stageCompanions(tmp, companions); err != nil {
+		return nil, nil, err
+	}
+	// move safely (as the current binary could be running) the newest version in place
+	if err := cfs.SafeMove(filepath.Join(tmp, filepath.FromSlash(binaries[0])), dest, cfs.WithPerm(cfs.RwxRxRxRx)); err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("safe move: %w", err), unstageCompanions(companions))
+	}
+	var backups map[string]string
+	if ro.keepBackups() {
+		if backups, err = backupCompanions(companions); err != nil {
+			return nil, nil, errors.Join(err, unstageCompanions(companions))
+		}
+	}
+	files, err := commitCompanions(companions, backups)
+	if err != nil || ro.layoutRoot == "" {
+		return files, backups, err
+	}
+	if err := saveLinks(filepath.Dir(dest), links); err != nil {
+		return nil, nil, fmt.Errorf("save links: %w", err)
+	}
+	return nil, nil, nil
+}
+
+// lookup validates Run (or Check) inputs, retrieves all releases with getReleases
+// and returns the appropriate release depending on input options.
+func lookup(ctx context.Context, repo string, getReleases GetReleases, opts ...RunOption) (runOptions, *Release, error) {
 	if repo == "" {
-		return "", ErrNoProjectName
+		return runOptions{}, nil, ErrNoProjectName
 	}
 	if getReleases == nil {
-		return "", ErrNoGetReleases
+		return runOptions{}, nil, ErrNoGetReleases
 	}
 
 	ro, err := newRunOpt(opts...)
 	if err != nil {
-		return "", err
+		return runOptions{}, nil, err
 	}
 
 	releases, err := getReleases(ctx, ro.httpClient)
 	if err != nil {
-		return "", fmt.Errorf("get releases: %w", err)
+		return runOptions{}, nil, fmt.Errorf("get releases: %w", err)
 	}
 
 	release, ok := findRelease(releases, ro.releaseOptions)
 	if !ok {
-		return "", ErrNoNewVersion
+		if ro.Version != "" {
+			return runOptions{}, nil, &VersionNotFoundError{Nearby: nearbyVersions(releases, ro.releaseOptions, ro.Version), Version: ro.Version}
+		}
+		return runOptions{}, nil, ErrNoNewVersion
 	}
 
-	s := _wordRegexp.FindAllString(semver.Prerelease(release.TagName), -1)
+	if release.resolve != nil {
+		assets, httpClient, err := release.resolve(ctx, ro.httpClient)
+		if err != nil {
+			return runOptions{}, nil, fmt.Errorf("get release assets: %w", err)
+		}
+		release.Assets, ro.httpClient = assets, httpClient
+	}
+	return ro, release, nil
+}
+
+// newTemplateData returns the templating data (for asset name, target name, etc.) of the input release.
+func newTemplateData(ro runOptions, repo string, release *Release) map[string]any {
+	version := ro.normalize(release.TagName)
 	var prerelease string
-	if len(s) > 0 {
-		// retrieve only the first element, in case there's '-beta.toto', etc. (weird cases)
-		// in any case semver.Prerelease already does the job to retrieve '-beta' with for instance v1.5.6-beta+meta
-		// but semver.Prerelease was missing the case of retrieving '-beta' with v1.5.6-beta.1, where it returned '-beta.1'
-		prerelease = s[0]
+	if channel := channelOf(release, version); channel != ChannelStable {
+		prerelease = channel
 	}
-	templateData := map[string]any{
+	return map[string]any{
 		"ArchiveExt": archiveExt(),
 		"BinExt":     binExt(),
+		"GOAMD64":    _goamd64(),
 		"GOARCH":     runtime.GOARCH,
 		"GOOS":       runtime.GOOS,
+		"Libc":       _libc(),
 		"Opts":       ro.releaseOptions,
 		"Prerelease": prerelease,
 		"Repo":       repo,
 		"Tag":        release.TagName,
+		"Version":    strings.TrimPrefix(version, "v"),
 	}
-
-	targetName, err := getTemplateValue(ro.targetTemplate, templateData)
-	if err != nil {
-		return "", fmt.Errorf("get target name: %w", err)
-	}
-	dest := filepath.Join(ro.destdir, targetName)
-
-	if currentVersion == release.TagName && cfs.Exists(dest) {
-		return release.TagName, ErrAlreadyInstalled
-	}
-
-	assetName, err := getTemplateValue(ro.assetTemplate, templateData)
-	if err != nil {
-		return "", fmt.Errorf("get asset name: %w", err)
-	}
-
-	if err := downloadAndMove(ctx, ro.httpClient, repo, release, assetName, dest); err != nil {
-		return "", err
-	}
-	return release.TagName, nil
 }
 
-// downloadAndMove downloads the provided assetName (if it exists) from the release and moves it into provided dest.
-func downloadAndMove(ctx context.Context, httpClient *http.Client, repo string, release *Release, assetName, dest string) error {
-	url, err := getDownloadURL(release, assetName)
+// download downloads the provided assetName (if it exists) from the release into a temporary directory (archives are extracted).
+//
+// It returns the temporary directory alongside the downloaded asset.
+func download(ctx context.Context, ro runOptions, repo string, release *Release, assetName, checksumName string) (string, Asset, error) {
+	asset, err := getDownloadURL(ctx, ro.httpClient, ro.verifier, release, assetName, checksumName)
 	if err != nil {
-		return fmt.Errorf("get download url: %w", err)
+		return "", Asset{}, fmt.Errorf("get download url: %w", err)
 	}
 
 	get := getter.Client{
 		DisableSymlinks: true,
-		Getters:         []getter.Getter{&getter.HttpGetter{Client: httpClient, XTerraformGetDisabled: true}},
+		Getters: []getter.Getter{
+			&getter.HttpGetter{Client: ro.httpClient, XTerraformGetDisabled: true},
+			&getter.FileGetter{}, // file:// URLs (e.g. with DirReleases)
+		},
 	}
 	// download in temporary directory the release (since we only want to move, rename and keep the binary)
-	tmp := filepath.Join(os.TempDir(), repo, release.TagName)
-	if _, err := get.Get(ctx, &getter.Request{Src: url, Dst: tmp, GetMode: getter.ModeAny}); err != nil {
-		return fmt.Errorf("download asset(s): %w", err)
-	}
-
-	var p string
-	if file := filepath.Join(tmp, filepath.Base(url)); cfs.Exists(file) {
-		p = file
-	} else if file := filepath.Join(tmp, repo+binExt()); cfs.Exists(file) {
-		p = file
-	} else {
-		return errors.New("unable to determine binary name to install, please open a issue")
+	tmp, err := os.MkdirTemp("", repo+"-*")
+	if err != nil {
+		return "", Asset{}, fmt.Errorf("create temporary directory: %w", err)
 	}
-
-	// move safely (as the current binary could be running) the newest version in place
-	if err := cfs.SafeMove(p, dest, cfs.WithPerm(cfs.RwxRxRxRx)); err != nil {
-		return fmt.Errorf("safe move: %w", err)
+	if _, err := get.Get(ctx, &getter.Request{Src: asset.DownloadURL, Dst: tmp, GetMode: getter.ModeAny, Copy: true}); err != nil {
+		var cerr *getter.ChecksumError
+		if errors.As(err, &cerr) { // checksum mismatch is only detected once the asset is downloaded
+			err = &VerificationError{Asset: assetName, Err: err}
+		}
+		return "", Asset{}, errors.Join(fmt.Errorf("download asset(s): %w", err), os.RemoveAll(tmp))
 	}
-	return nil
+	return tmp, asset, nil
 }
 
 // releaseOptions is the struct will all options for releases filtering.
 type releaseOptions struct {
+	Channel     string
 	Prereleases bool
 	Major       string
 	Minor       string
+	Constraint  *Constraint
+	TagPrefix   string
+	Version     string
+}
+
+// normalize returns the input version (or tag) without the tag prefix (see WithTagPrefix) and with a 'v' prefix,
+// e.g. 'v1.2.3' for both '1.2.3' and 'mytool/v1.2.3' (with 'mytool/' tag prefix).
+func (o releaseOptions) normalize(version string) string {
+	version = strings.TrimPrefix(version, o.TagPrefix)
+	if !strings.HasPrefix(version, "v") {
+		return "v" + version
+	}
+	return version
+}
+
+// version returns the semver version of the input tag (see normalize).
+//
+// It returns false when the tag doesn't start with the tag prefix or when it isn't a valid semver version.
+func (o releaseOptions) version(tag string) (string, bool) {
+	if !strings.HasPrefix(tag, o.TagPrefix) {
+		return "", false
+	}
+	version := o.normalize(tag)
+	return version, semver.IsValid(version)
+}
+
+// candidate represents a release alongside its semver version.
+type candidate struct {
+	release Release
+	version string
+}
+
+// channel returns the channel of the candidate (see channelOf).
+func (c candidate) channel() string {
+	return channelOf(&c.release, c.version)
+}
+
+// candidates returns all releases with a valid semver version (see releaseOptions.version), sorted by version.
+//
+// Draft releases are removed.
+func candidates(releases []Release, opts releaseOptions) []candidate {
+	result := make([]candidate, 0, len(releases))
+	for _, release := range releases {
+		version, ok := opts.version(release.TagName)
+		if !ok || release.Draft {
+			continue
+		}
+		result = append(result, candidate{release: release, version: version})
+	}
+	slices.SortStableFunc(result, func(c1, c2 candidate) int {
+		return semver.Compare(c1.version, c2.version)
+	})
+	return result
 }
 
 // findRelease finds the appropriate release to install in the input slice of releases depending on search version and provided options.
 func findRelease(releases []Release, opts releaseOptions) (*Release, bool) {
-	// remove all invalid semver releases or draft releases
-	candidates := slices.DeleteFunc(releases, func(r Release) bool { return !semver.IsValid(r.TagName) })
+	candidates := candidates(releases, opts)
+
+	// retrieve exactly the given version (even if it's a prerelease or an older version)
+	if opts.Version != "" {
+		index := slices.IndexFunc(candidates, func(c candidate) bool { return semver.Compare(c.version, opts.Version) == 0 })
+		if index < 0 {
+			return nil, false
+		}
+		return &candidates[index].release, true
+	}
 
 	// keep only versions related to given major version
 	if opts.Major != "" {
-		candidates = slices.DeleteFunc(candidates, func(r Release) bool { return semver.Major(r.TagName) != opts.Major })
+		candidates = slices.DeleteFunc(candidates, func(c candidate) bool { return semver.Major(c.version) != opts.Major })
 	}
 	// keep only versions related to given minor version
 	if opts.Minor != "" {
-		candidates = slices.DeleteFunc(candidates, func(r Release) bool { return semver.MajorMinor(r.TagName) != opts.Minor })
+		candidates = slices.DeleteFunc(candidates, func(c candidate) bool { return semver.MajorMinor(c.version) != opts.Minor })
 	}
 
-	// sort all appropriate releases
-	slices.SortStableFunc(candidates, func(r1, r2 Release) int {
-		return semver.Compare(r1.TagName, r2.TagName)
-	})
+	// keep only versions satisfying given constraint
+	if opts.Constraint != nil {
+		candidates = slices.DeleteFunc(candidates, func(c candidate) bool { return !opts.Constraint.Check(c.version) })
+	}
+
+	// retrieve the least stable accepted channel
+	accepted := channelRank(ChannelStable)
+	if opts.Channel != "" {
+		accepted = channelRank(opts.Channel)
+	} else if opts.Prereleases {
+		accepted = channelRank(ChannelNightly)
+	}
 
 	// loop over the slice in reverse mode to retrieve the first appropriate version
-	// depending on whether prereleases are accepted or not
+	// depending on whether its channel is accepted or not
 	for i := len(candidates) - 1; i >= 0; i-- {
 		found := candidates[i]
 
-		// if prereleases aren't accepted and version is a prerelease
+		// if version channel is less stable than the accepted one
 		// continue since it cannot be installed
-		if !opts.Prereleases && semver.Prerelease(found.TagName) != "" {
+		if channelRank(found.channel()) > accepted {
 			continue
 		}
-		return &found, true
+		return &found.release, true
 	}
 	return nil, false
 }
 
-// getDownloadURL returns the right URL to use for downloading a release.
+// getDownloadURL returns the right asset to use for downloading a release.
 //
 // It's a specific function since download is handled by go-getter and that it can handle checksums verification.
-// As such, returned URL can be enriched with the checksums URL.
-func getDownloadURL(release *Release, assetName string) (string, error) {
-	var bin, checksum Asset
-	for _, asset := range release.Assets {
-		// find the right asset
-		if asset.Name == assetName {
-			bin = asset
+// As such, returned asset URL is enriched with the asset checksum when a checksums file is found (see findChecksum),
+// the checksums file signature being verified beforehand when a verifier is given.
+func getDownloadURL(ctx context.Context, httpClient *http.Client, verifier Verifier, release *Release, assetName, checksumName string) (Asset, error) {
+	bin, ok := findAsset(release, assetName)
+	if !ok {
+		return Asset{}, fmt.Errorf("no valid release asset found with suffix '%s'", assetName)
+	}
+
+	// find checksum file in assets for verification during download
+	checksums, ok := findChecksum(release, assetName, checksumName)
+	if !ok {
+		if verifier != nil {
+			return Asset{}, &VerificationError{Asset: bin.Name, Err: errors.New("no checksums file found in release assets")}
+		}
+		return bin, nil
+	}
+
+	content, err := getBytes(ctx, httpClient, checksums)
+	if err != nil {
+		return Asset{}, fmt.Errorf("get checksums: %w", err)
+	}
+	if verifier != nil {
+		if err := verifyChecksums(ctx, httpClient, release, checksums, content, verifier); err != nil {
+			return Asset{}, err
 		}
+	}
 
-		// find checksum file in assets for verification during download
-		if asset.Name == "checksums.txt" {
-			checksum = asset
+	algorithm, checksum, ok := parseChecksum(content, checksums.Name, bin.Name)
+	if !ok {
+		err := fmt.Errorf("no checksum found for '%s' in '%s'", bin.Name, checksums.Name)
+		if verifier != nil {
+			return Asset{}, &VerificationError{Asset: bin.Name, Err: err}
 		}
+		return Asset{}, err
+	}
+	if verifier != nil && weakChecksum(algorithm) {
+		return Asset{}, &VerificationError{Asset: bin.Name, Err: fmt.Errorf("weak checksum algorithm '%s' in '%s' can't be trusted", algorithm, checksums.Name)}
 	}
+	bin.DownloadURL = withQuery(bin.DownloadURL, "checksum="+algorithm+":"+checksum)
+	return bin, nil
+}
 
-	if bin == (Asset{}) {
-		return "", fmt.Errorf("no valid release asset found with suffix '%s'", assetName)
+// findAsset returns the asset with the input name in release assets.
+func findAsset(release *Release, name string) (Asset, bool) {
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
 	}
+	return Asset{}, false
+}
 
-	if checksum != (Asset{}) {
-		return fmt.Sprintf("%s?checksum=file:%s", bin.DownloadURL, checksum.DownloadURL), nil
+// withQuery returns the input URL with the raw query parameter appended.
+func withQuery(rawURL, param string) string {
+	if strings.Contains(rawURL, "?") {
+		return rawURL + "&" + param
+	}
+	return rawURL + "?" + param
+}
+
+// urlBase returns the last element of the input URL path (without its query).
+func urlBase(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return filepath.Base(rawURL)
 	}
-	return bin.DownloadURL, nil
+	return path.Base(u.Path)
 }
 
 // binExt returns the appropriate extension for a binary depending on current GOOS.
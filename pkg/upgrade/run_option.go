@@ -7,22 +7,41 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-cleanhttp"
+	"golang.org/x/mod/semver"
 )
 
 var (
 	_majorRegexp = regexp.MustCompile("^v[0-9]+$")
 	_minorRegexp = regexp.MustCompile(`^v[0-9]+\.[0-9]+$`)
-	_wordRegexp  = regexp.MustCompile(`[a-zA-Z]+`)
+
+	// _wordRegexp retrieves the words of a semver prerelease, since semver.Prerelease returns '-beta' for v1.5.6-beta+meta
+	// but '-beta.1' for v1.5.6-beta.1 (or '-beta.toto' in weird cases).
+	_wordRegexp = regexp.MustCompile(`[a-zA-Z]+`)
 )
 
 var (
 	// ErrMajorMinorExclusive is the error returned when both options WithMajor and WithMinor are given and non empty.
 	ErrMajorMinorExclusive = errors.New("both major and minor option are mutually exclusive")
 
+	// ErrSelfUpdateKeepVersionsExclusive is the error returned when both options WithSelfUpdate and WithKeepVersions are enabled.
+	ErrSelfUpdateKeepVersionsExclusive = errors.New("both self update and keep versions options are mutually exclusive")
+
+	// ErrVersionedLayoutExclusive is the error returned when WithVersionedLayout is given alongside WithKeepVersions or WithSelfUpdate.
+	ErrVersionedLayoutExclusive = errors.New("versioned layout option is mutually exclusive with keep versions and self update options")
+
+	// ErrVersionedLayoutWindows is the error returned when WithVersionedLayout is given on windows,
+	// where symbolic links often require elevated privileges.
+	ErrVersionedLayoutWindows = errors.New("versioned layout option isn't supported on windows")
+
+	// ErrVersionExclusive is the error returned when WithVersion is given alongside WithConstraint, WithMajor or WithMinor.
+	ErrVersionExclusive = errors.New("version option is mutually exclusive with constraint, major and minor options")
+
 	// ErrInvalidOptions is the error returned when there's at least one invalid option.
 	ErrInvalidOptions = errors.New("invalid options")
 )
@@ -30,18 +49,175 @@ var (
 // RunOption is the right function to tune Run function with specific behaviors.
 type RunOption func(*runOptions) error
 
-// WithAssetTemplate specifies the asset name to match (equal) for during asset finding (to retrieve the appropriate one to install).
+// WithAssetTemplate specifies the asset name to match for during asset finding (to retrieve the appropriate one to install).
 //
 // By default it's:
 //
 //	{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}{{ .ArchiveExt }}
 //
+// The rendered value is matched against release assets names:
+//
+//   - as a regexp when it starts with 're:' (e.g. 're:{{ .Repo }}_(v?[0-9.]+_)?{{ .GOOS }}_{{ .GOARCH }}\.tar\.gz'), the whole name must match (case insensitive)
+//   - as a glob when it contains '*', '?' or '[' (e.g. '{{ .Repo }}_*_{{ .GOOS }}_{{ .GOARCH }}.tar.gz')
+//   - exactly otherwise
+//
+// When no asset matches an exact or glob value literally, it's tried again case insensitively
+// and with GOOS and GOARCH usual aliases (e.g. 'x86_64' or 'x64' for 'amd64', 'aarch64' for 'arm64', 'macos' for 'darwin').
+// Checksums files and signatures are never matched by regexps and globs.
+//
+// See WithAssetTemplates to give fallback templates.
+//
 // Various functions are available: 'lower', 'title', 'upper'.
 //
-// Various variables are available: 'ArchiveExt', 'BinExt', 'GOOS', 'GOARCH', 'Opts' (.Major, .Minor, .Prereleases), 'Repo', 'Tag'.
+// Various variables are available: 'ArchiveExt', 'BinExt', 'GOOS', 'GOARCH', 'Opts' (.Channel, .Constraint, .Major, .Minor, .Prereleases, .TagPrefix, .Version),
+// 'Prerelease' (the release channel when it's a prerelease, see WithChannel), 'Repo', 'Tag',
+// 'Version' (the tag without its prefix and without 'v', e.g. '1.2.3' for 'mytool/v1.2.3'),
+// 'Libc' ('gnu' or 'musl' on linux, empty otherwise), 'GOAMD64' (the host x86-64 microarchitecture level, e.g. 'v3', empty on other architectures).
+//
+// When 'GOAMD64' is used and no asset matches the host level, lower levels are tried down to 'v1', e.g.:
+//
+//	{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}{{ if .GOAMD64 }}_{{ .GOAMD64 }}{{ end }}{{ if .Libc }}-{{ .Libc }}{{ end }}{{ .ArchiveExt }}
 func WithAssetTemplate(assetTemplate string) RunOption {
 	return func(o *runOptions) error {
-		o.assetTemplate = assetTemplate
+		o.assetTemplates = []string{assetTemplate}
+		return nil
+	}
+}
+
+// WithAssetTemplates specifies a ranked list of asset templates (see WithAssetTemplate),
+// the installed asset is the first one matching the first template, or else the second template, etc.
+//
+// When no asset matches, Run fails with an error listing all release assets names.
+func WithAssetTemplates(assetTemplates ...string) RunOption {
+	return func(o *runOptions) error {
+		o.assetTemplates = slices.DeleteFunc(slices.Clone(assetTemplates), func(assetTemplate string) bool { return assetTemplate == "" })
+		return nil
+	}
+}
+
+// WithBinaryPath specifies the binaries to install from the downloaded asset (e.g. an archive containing multiple files),
+// as glob patterns relative to the archive root, e.g. '{{ .Repo }}_{{ .Version }}_{{ .GOOS }}_{{ .GOARCH }}/bin/{{ .Repo }}{{ .BinExt }}' or '*/bin/*'.
+//
+// A pattern containing a '/' is matched against the whole file path, otherwise it's matched against file names at any depth (e.g. '{{ .Repo }}{{ .BinExt }}').
+// Each pattern must match at least one file.
+//
+// The first matching file is installed as the target (see WithTargetTemplate), the other ones are installed next to it with their own name,
+// which allows installing multiple binaries from one archive, e.g. WithBinaryPath("{{ .Repo }}", "{{ .Repo }}-server").
+//
+// Same functions and variables as WithAssetTemplate are available.
+//
+// By default, the installed binary is the first file (at any depth) named as the asset download URL base name, the asset name or '{{ .Repo }}{{ .BinExt }}'.
+func WithBinaryPath(patterns ...string) RunOption {
+	return func(ro *runOptions) error {
+		ro.binaryPaths = slices.DeleteFunc(slices.Clone(patterns), func(pattern string) bool { return pattern == "" })
+		return nil
+	}
+}
+
+// WithChecksumTemplate specifies the checksums file name to use to verify the downloaded asset.
+//
+// By default, the checksums file is detected in release assets with the following conventions (in order):
+// 'checksums.txt', '<anything>checksums.txt' (e.g. 'repo_1.2.3_checksums.txt'), 'SHA512SUMS', '<asset>.sha512', 'SHA256SUMS' and '<asset>.sha256'
+// ('SHA512SUMS' and 'SHA256SUMS' being also detected in lowercase and with '.txt' extension).
+//
+// The checksum algorithm is read from the checksums file name (e.g. 'SHA512SUMS') or guessed from the checksum length.
+//
+// Same functions and variables as WithAssetTemplate are available, with the addition of 'Asset' (the asset name), e.g.:
+//
+//	{{ .Asset }}.sha256
+func WithChecksumTemplate(checksumTemplate string) RunOption {
+	return func(o *runOptions) error {
+		o.checksumTemplate = checksumTemplate
+		return nil
+	}
+}
+
+// WithChannel specifies the least stable release channel to consider for upgrade / installation,
+// channels being ordered from the most stable one to the least stable one:
+//
+//   - ChannelStable ('stable'): releases which aren't prereleases
+//   - ChannelRC ('rc'): release candidates (e.g. v1.0.0-rc.1, v1.0.0-pre.1 or v1.0.0-preview)
+//   - ChannelBeta ('beta'): beta prereleases (e.g. v1.0.0-beta.1)
+//   - ChannelAlpha ('alpha'): alpha prereleases (e.g. v1.0.0-alpha.1)
+//   - ChannelNightly ('nightly'): nightly prereleases (e.g. v1.0.0-nightly.20240601) and any other prerelease kind
+//
+// For instance, with 'rc' channel, stable releases and release candidates are considered but never beta or alpha prereleases.
+// Releases flagged as prerelease by their source without any prerelease in their tag are release candidates.
+//
+// The template variable 'Prerelease' is the channel of the installed release (empty for stable releases).
+// It takes precedence over WithPrereleases (which is the same as 'nightly' channel).
+func WithChannel(channel string) RunOption {
+	return func(ro *runOptions) error {
+		ro.Channel = channel
+		if channel != "" && channelRank(channel) < 0 {
+			return fmt.Errorf("invalid channel '%s'", channel)
+		}
+		return nil
+	}
+}
+
+// WithCompanion specifies to install the files of the downloaded asset (e.g. an archive) matching the glob pattern
+// into the given directory (e.g. shell completions, man pages or docs), pattern matching is the same as WithBinaryPath.
+//
+// Both the pattern and the directory are templated, with the same functions and variables as WithAssetTemplate
+// and the addition of 'DataHome' (${XDG_DATA_HOME} or else ${HOME}/.local/share), e.g.:
+//
+//	WithCompanion("docs/*.md", "{{ .DataHome }}/doc/{{ .Repo }}")
+//
+// Companions are optional (patterns matching no file are ignored) and are installed alongside the binary,
+// they're staged next to their destination and moved in place only once the binary is installed.
+// Installed companions (and the replaced ones) are recorded in the state directory to be restored with Rollback or removed with Uninstall.
+//
+// It can be given multiple times. See WithCompletions and WithManPages for usual companions.
+func WithCompanion(pattern, dir string) RunOption {
+	return func(ro *runOptions) error {
+		if pattern == "" || dir == "" {
+			return fmt.Errorf("invalid companion '%s' to '%s'", pattern, dir)
+		}
+		ro.companions = append(ro.companions, companion{dir: dir, pattern: pattern})
+		return nil
+	}
+}
+
+// WithCompletions specifies to install shell completions files from the downloaded asset (see WithCompanion) into their usual user directories:
+//
+//   - bash completions into '{{ .DataHome }}/bash-completion/completions'
+//   - zsh completions into '{{ .DataHome }}/zsh/site-functions'
+//   - fish completions into '{{ .DataHome }}/fish/vendor_completions.d'
+//
+// Patterns are glob patterns (e.g. 'completions/*.bash' or 'completions/_{{ .Repo }}'), an empty one is ignored.
+func WithCompletions(bash, zsh, fish string) RunOption {
+	return func(ro *runOptions) error {
+		for _, c := range []companion{
+			{dir: "{{ .DataHome }}/bash-completion/completions", pattern: bash},
+			{dir: "{{ .DataHome }}/zsh/site-functions", pattern: zsh},
+			{dir: "{{ .DataHome }}/fish/vendor_completions.d", pattern: fish},
+		} {
+			if c.pattern != "" {
+				ro.companions = append(ro.companions, c)
+			}
+		}
+		return nil
+	}
+}
+
+// WithConstraint specifies a semver constraint the upgraded / installed version must satisfy,
+// e.g. '>=1.4.0, <2.0.0, !=1.7.0' to install any v1 version from v1.4.0 except v1.7.0 (see Constraint for the whole syntax).
+//
+// It can be combined with WithMajor or WithMinor (all of them must be satisfied).
+// Prereleases are still only considered with WithPrereleases (or WithChannel).
+// An empty constraint is ignored (any version is considered).
+func WithConstraint(constraint string) RunOption {
+	return func(ro *runOptions) error {
+		if constraint == "" {
+			ro.Constraint = nil
+			return nil
+		}
+		c, err := ParseConstraint(constraint)
+		if err != nil {
+			return fmt.Errorf("invalid constraint '%s': %w", constraint, err)
+		}
+		ro.Constraint = c
 		return nil
 	}
 }
@@ -56,6 +232,41 @@ func WithDestination(destdir string) RunOption {
 	}
 }
 
+// WithGoInstall specifies to build and install the provided package (e.g. github.com/owner/repo/cmd/repo)
+// with 'go install' instead of downloading a release asset.
+//
+// It's meant to be used with GoProxyReleases for tools which don't publish any binary.
+// The go command must be available in PATH and is executed with the current environment (GOPROXY, GOFLAGS, etc.).
+func WithGoInstall(pkg string) RunOption {
+	return func(ro *runOptions) error {
+		ro.goInstall = pkg
+		return nil
+	}
+}
+
+// WithHealthCheck specifies to execute the installed binary with the provided arguments (e.g. 'version' or '--version')
+// once installed and to ensure its output contains the installed version (with or without 'v' prefix).
+//
+// In case the execution fails, exceeds the timeout or doesn't print the installed version,
+// the previous binary is restored (or the installed one is removed if there wasn't any) and Run fails with an error wrapping ErrHealthCheck.
+//
+// By default (or with 0), timeout is 10 seconds. Arguments default to '--version'.
+func WithHealthCheck(timeout time.Duration, args ...string) RunOption {
+	return func(ro *runOptions) error {
+		if timeout < 0 {
+			return fmt.Errorf("invalid health check timeout '%s'", timeout)
+		}
+		if timeout == 0 {
+			timeout = 10 * time.Second
+		}
+		if len(args) == 0 {
+			args = []string{"--version"}
+		}
+		ro.healthCheck = &healthCheck{args: args, timeout: timeout}
+		return nil
+	}
+}
+
 // WithHTTPClient specifies the http client to use for both GetReleases function
 // and asset(s) download(s).
 //
@@ -67,6 +278,79 @@ func WithHTTPClient(client *http.Client) RunOption {
 	}
 }
 
+// WithKeepVersions specifies whether older installed versions must be kept side by side.
+//
+// When enabled, each release is installed into a versioned file next to the target (e.g. 'repo-v1.4.2' or 'repo-v1.4.2.exe')
+// and the target (e.g. 'repo') points to the newest installed one, which allows a quick rollback in case of a broken release.
+//
+// The target is a symbolic link to the versioned file, except on windows where the versioned file is copied.
+//
+// See WithRetention to prune older versions.
+func WithKeepVersions(keep bool) RunOption {
+	return func(ro *runOptions) error {
+		ro.keepVersions = keep
+		return nil
+	}
+}
+
+// WithRetention specifies the maximum number of versioned installations to keep (current one included)
+// when WithKeepVersions or WithVersionedLayout is enabled. Oldest versions are removed after a successful installation.
+//
+// By default (or with 0) all versions are kept.
+func WithRetention(count int) RunOption {
+	return func(ro *runOptions) error {
+		ro.retention = count
+		if count < 0 {
+			return fmt.Errorf("invalid retention '%d'", count)
+		}
+		return nil
+	}
+}
+
+// WithSelfUpdate specifies to replace the running executable (see os.Executable, symbolic links are followed)
+// instead of installing into the destination directory (WithDestination and WithTargetTemplate are ignored).
+//
+// The replaced executable is kept next to it as a backup with '.old' suffix (e.g. '/usr/local/bin/repo.old' or 'repo.old.exe').
+//
+// In case the current user can't write in the executable directory (e.g. /usr/local/bin), Run fails early
+// with an error wrapping fs.ErrPermission.
+//
+// It's mutually exclusive with WithKeepVersions.
+func WithSelfUpdate(selfUpdate bool) RunOption {
+	return func(ro *runOptions) error {
+		ro.selfUpdate = selfUpdate
+		return nil
+	}
+}
+
+// WithStateDir specifies the directory where installations are recorded, which enables Rollback and Uninstall
+// (the same directory must be given to them), e.g. a directory in os.UserCacheDir.
+//
+// Each repo installation is recorded in its own file ('<repo>.install.json'), as such the same directory can be shared by multiple repos.
+//
+// When given, the replaced binary is kept next to the installed one with '.old' suffix (see Rollback).
+// By default, installations aren't recorded and replaced binaries aren't kept (except with WithSelfUpdate).
+func WithStateDir(dir string) RunOption {
+	return func(ro *runOptions) error {
+		ro.stateDir = dir
+		return nil
+	}
+}
+
+// WithTagPrefix specifies the prefix of release tags to consider, other releases are ignored.
+//
+// It's useful for repositories hosting multiple tools (monorepos) with tags like 'mytool/v1.2.3' or 'mytool-v1.2.3',
+// in which case the prefix would be 'mytool/' or 'mytool-'.
+//
+// The prefix is removed from tags before comparing versions, as is the current version given to Run if it has this prefix.
+// Note that tags without 'v' prefix (e.g. '1.2.3' or 'mytool-1.2.3') are accepted, with or without this option.
+func WithTagPrefix(prefix string) RunOption {
+	return func(ro *runOptions) error {
+		ro.TagPrefix = prefix
+		return nil
+	}
+}
+
 // WithTargetTemplate specifies the target name of the installed binary.
 //
 // By default it's
@@ -75,15 +359,16 @@ func WithHTTPClient(client *http.Client) RunOption {
 //	{{- if ne .Opts.Major "" }}{{ print "-" .Opts.Major }}
 //	{{- else if ne .Opts.Minor "" }}{{ print "-" .Opts.Minor }}
 //	{{- end }}
-//	{{- if and .Opts.Prereleases (ne .Prerelease "") }}{{ print "-" .Prerelease }}{{ end }}
+//	{{- if and (or .Opts.Prereleases .Opts.Channel) (ne .Prerelease "") }}{{ print "-" .Prerelease }}{{ end }}
 //	{{- .BinExt }}
 //
-// which gives 'repo-pre' or 'repo-v1.exe', or 'repo.exe' or 'repo' or 'repo-v1.6', etc.
+// which gives 'repo-rc' or 'repo-beta' or 'repo-v1.exe', or 'repo.exe' or 'repo' or 'repo-v1.6', etc.
 // depending on inputs options and whether the installed version is a prerelease or not.
 //
 // Various functions are available: 'lower', 'title', 'upper'.
 //
-// Various variables are available: 'ArchiveExt', 'BinExt', 'GOOS', 'GOARCH', 'Opts' (with inputs WithMajor, WithMinor and WithPrerelease), 'Repo', 'Tag'.
+// Various variables are available: 'ArchiveExt', 'BinExt', 'GOAMD64', 'GOOS', 'GOARCH', 'Libc', 'Opts' (with inputs WithChannel, WithConstraint, WithMajor, WithMinor, WithPrerelease, WithTagPrefix and WithVersion),
+// 'Prerelease', 'Repo', 'Tag', 'Version'.
 //
 // Note that it's not recommended to use this option since if badly defined a prerelease installation could override the latest stable installation
 // or an old installation could override it too, etc.
@@ -108,6 +393,20 @@ func WithMajor(major string) RunOption {
 	}
 }
 
+// WithManPages specifies to install man pages from the downloaded asset (see WithCompanion) of section 1 (user commands)
+// into '{{ .DataHome }}/man/man1', e.g. 'man/*.1' or 'man/*.1.gz'.
+//
+// WithCompanion can be used for other sections, e.g. WithCompanion("man/*.5", "{{ .DataHome }}/man/man5").
+// An empty pattern is ignored.
+func WithManPages(pattern string) RunOption {
+	return func(ro *runOptions) error {
+		if pattern != "" {
+			ro.companions = append(ro.companions, companion{dir: "{{ .DataHome }}/man/man1", pattern: pattern})
+		}
+		return nil
+	}
+}
+
 // WithMinor specifies if upgraded / installed package must concern a specific minor version.
 //
 // By default all minor versions can be used (outside of prereleases which can be included with WithPrerelease).
@@ -122,6 +421,11 @@ func WithMinor(minor string) RunOption {
 }
 
 // WithPrereleases specifies whether prerelease versions can be considered for upgrade / installation.
+//
+// A release is a prerelease when its tag is a semver prerelease (e.g. v1.5.0-beta.1)
+// or when it's flagged as such by its source (e.g. GitHub prerelease flag).
+//
+// It accepts all prereleases kinds, see WithChannel to only accept the most stable ones.
 func WithPrereleases(accepted bool) RunOption {
 	return func(ro *runOptions) error {
 		ro.Prereleases = accepted
@@ -129,14 +433,116 @@ func WithPrereleases(accepted bool) RunOption {
 	}
 }
 
+// WithVersion specifies the exact version to install (e.g. 'v1.8.3' or '1.8.3'), be it older than the current one (downgrade)
+// or a prerelease (WithPrereleases isn't needed).
+//
+// It's useful to pin a tool to a specific version (e.g. from a lockfile).
+// The version must be given without the tag prefix (see WithTagPrefix).
+// When the version doesn't exist, Run fails with a VersionNotFoundError listing nearby versions.
+//
+// It's mutually exclusive with WithConstraint, WithMajor and WithMinor.
+func WithVersion(version string) RunOption {
+	return func(ro *runOptions) error {
+		if version == "" {
+			ro.Version = ""
+			return nil
+		}
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+		ro.Version = version
+		// ensure version is a full semver version (vX.Y.Z with optional prerelease and build)
+		if !semver.IsValid(version) || semver.Canonical(version) != strings.SplitN(version, "+", 2)[0] {
+			return fmt.Errorf("invalid version '%s'", version)
+		}
+		return nil
+	}
+}
+
+// WithVersionedLayout specifies to install each release into its own directory '<root>/<repo>/<version>/'
+// (e.g. '/opt/tools/repo/v1.4.2/repo', the version being the tag without its prefix, see WithTagPrefix)
+// and to make the target (see WithDestination and WithTargetTemplate) a symbolic link to the installed version,
+// atomically swapped once the installation succeeded (and the health check too, see WithHealthCheck).
+//
+// Other binaries (see WithBinaryPath) and companions (see WithCompanion) are installed in the same version directory
+// and linked into their destination (next to the target for binaries) when the version is activated.
+// Installing a version which was already installed is instant since only the symbolic links are swapped (nothing is downloaded)
+// and Rollback is a symbolic links flip. Versions coexist and can be executed directly from their directory.
+//
+// See WithRetention to prune older versions. It's mutually exclusive with WithKeepVersions and WithSelfUpdate
+// and isn't supported on windows (ErrVersionedLayoutWindows is returned).
+func WithVersionedLayout(root string) RunOption {
+	return func(ro *runOptions) error {
+		ro.layoutRoot = root
+		return nil
+	}
+}
+
+// WithVerifier specifies a Verifier to verify the signature of the release checksums file (checksums.txt) before downloading the asset.
+//
+// When given, the release must provide both a checksums file listing the asset and its signature (see Verifier.Signature),
+// otherwise Run fails with a VerificationError. Weak checksums (md5 and sha1) are rejected the same way.
+//
+// See WithMinisign and WithCosign for built-in verifiers.
+func WithVerifier(verifier Verifier) RunOption {
+	return func(ro *runOptions) error {
+		ro.verifier = verifier
+		return nil
+	}
+}
+
+// WithMinisign specifies to verify the release checksums file (checksums.txt) with its minisign signature (checksums.txt.minisig).
+//
+// The public key can be either the whole minisign public key file or only its base64 line (e.g. RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3).
+//
+// See WithVerifier for more details.
+func WithMinisign(publicKey string) RunOption {
+	return func(ro *runOptions) error {
+		verifier, err := newMinisignVerifier(publicKey)
+		if err != nil {
+			return err
+		}
+		ro.verifier = verifier
+		return nil
+	}
+}
+
+// WithCosign specifies to verify the release checksums file (checksums.txt) with its cosign keyed blob signature (checksums.txt.sig),
+// as generated by 'cosign sign-blob --key cosign.key --output-signature checksums.txt.sig checksums.txt'.
+//
+// The public key must be PEM encoded (e.g. cosign.pub content).
+//
+// See WithVerifier for more details.
+func WithCosign(publicKey []byte) RunOption {
+	return func(ro *runOptions) error {
+		verifier, err := newCosignVerifier(publicKey)
+		if err != nil {
+			return err
+		}
+		ro.verifier = verifier
+		return nil
+	}
+}
+
 // runOptions is the struct related to Option function(s) defining all optional properties.
 type runOptions struct {
 	releaseOptions
 
-	assetTemplate  string
-	destdir        string
-	httpClient     *http.Client
-	targetTemplate string
+	assetTemplates   []string
+	binaryPaths      []string
+	checksumTemplate string
+	companions       []companion
+	destdir          string
+	goInstall        string
+	healthCheck      *healthCheck
+	httpClient       *http.Client
+	keepVersions     bool
+	layoutRoot       string
+	retention        int
+	selfUpdate       bool
+	stateDir         string
+	targetTemplate   string
+	verifier         Verifier
 }
 
 // newRunOpt creates a new option struct with all input Option functions
@@ -158,6 +564,18 @@ func newRunOpt(opts ...RunOption) (runOptions, error) {
 	if ro.Major != "" && ro.Minor != "" {
 		errs = append(errs, ErrMajorMinorExclusive)
 	}
+	if ro.Version != "" && (ro.Constraint != nil || ro.Major != "" || ro.Minor != "") {
+		errs = append(errs, ErrVersionExclusive)
+	}
+	if ro.selfUpdate && ro.keepVersions {
+		errs = append(errs, ErrSelfUpdateKeepVersionsExclusive)
+	}
+	if ro.layoutRoot != "" && (ro.keepVersions || ro.selfUpdate) {
+		errs = append(errs, ErrVersionedLayoutExclusive)
+	}
+	if ro.layoutRoot != "" && runtime.GOOS == "windows" {
+		errs = append(errs, ErrVersionedLayoutWindows)
+	}
 	if len(errs) > 0 {
 		errs = slices.Insert(errs, 0, ErrInvalidOptions)
 		ef := make([]any, 0, len(errs))
@@ -169,8 +587,8 @@ func newRunOpt(opts ...RunOption) (runOptions, error) {
 		return ro, fmt.Errorf(strings.Join(wraps, ": "), ef...)
 	}
 
-	if ro.assetTemplate == "" {
-		ro.assetTemplate = `{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}{{ .ArchiveExt }}`
+	if len(ro.assetTemplates) == 0 || (len(ro.assetTemplates) == 1 && ro.assetTemplates[0] == "") {
+		ro.assetTemplates = []string{`{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}{{ .ArchiveExt }}`}
 	}
 	if ro.destdir == "" {
 		home, _ := os.UserHomeDir()
@@ -185,9 +603,20 @@ func newRunOpt(opts ...RunOption) (runOptions, error) {
 {{- if ne .Opts.Major "" }}{{ print "-" .Opts.Major }}
 {{- else if ne .Opts.Minor "" }}{{ print "-" .Opts.Minor }}
 {{- end }}
-{{- if and .Opts.Prereleases (ne .Prerelease "") }}{{ print "-" .Prerelease }}{{ end }}
+{{- if and (or .Opts.Prereleases .Opts.Channel) (ne .Prerelease "") }}{{ print "-" .Prerelease }}{{ end }}
 {{- .BinExt }}`
 	}
 
 	return ro, nil
 }
+
+// keepBackups returns true when replaced files must be kept as backups,
+// either for Rollback (see WithStateDir) or to restore them in case of failure (see WithSelfUpdate and WithHealthCheck).
+func (ro runOptions) keepBackups() bool {
+	return ro.stateDir != "" || ro.selfUpdate || ro.healthCheck != nil
+}
+
+// installFile returns the path of the install record of repo in state directory (see WithStateDir).
+func (ro runOptions) installFile(repo string) string {
+	return filepath.Join(ro.stateDir, repo+".install.json")
+}
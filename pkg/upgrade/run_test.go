@@ -4,17 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v63/github"
 	"github.com/hashicorp/go-cleanhttp"
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
 
 	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
 	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
@@ -32,9 +36,6 @@ func TestRun(t *testing.T) {
 	httpmock.ActivateNonDefault(httpClient)
 	t.Cleanup(httpmock.DeactivateAndReset)
 
-	// download path defined in run.go for a
-	getterCleanup := func() { assert.NoError(t, os.RemoveAll(filepath.Join(os.TempDir(), "repo"))) }
-
 	getReleases := upgrade.GithubReleases("owner", "repo")
 
 	t.Run("error_missing_project_name", func(t *testing.T) {
@@ -71,6 +72,79 @@ func TestRun(t *testing.T) {
 		assert.ErrorContains(t, err, "invalid minor version")
 	})
 
+	t.Run("error_invalid_constraint_option", func(t *testing.T) {
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", getReleases, upgrade.WithConstraint(">=invalid"))
+
+		// Assert
+		assert.ErrorContains(t, err, upgrade.ErrInvalidOptions.Error())
+		assert.ErrorContains(t, err, "invalid constraint '>=invalid'")
+	})
+
+	t.Run("error_invalid_version_option", func(t *testing.T) {
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", getReleases, upgrade.WithVersion("1.8"))
+
+		// Assert
+		assert.ErrorContains(t, err, upgrade.ErrInvalidOptions.Error())
+		assert.ErrorContains(t, err, "invalid version 'v1.8'")
+	})
+
+	t.Run("error_version_exclusive", func(t *testing.T) {
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", getReleases, upgrade.WithVersion("v1.8.3"), upgrade.WithMajor("v1"))
+
+		// Assert
+		assert.ErrorContains(t, err, upgrade.ErrInvalidOptions.Error())
+		assert.ErrorContains(t, err, upgrade.ErrVersionExclusive.Error())
+	})
+
+	t.Run("error_version_not_found", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		releasesURL := "https://api.github.com/repos/owner/repo/releases?page=1&per_page=100"
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{
+				{TagName: toPtr("v1.6.0")},
+				{TagName: toPtr("v1.7.0")},
+				{TagName: toPtr("v1.8.1")},
+				{TagName: toPtr("v1.8.2")},
+				{TagName: toPtr("v1.9.0")},
+				{TagName: toPtr("v2.0.0")},
+				{TagName: toPtr("v2.1.0")},
+				{TagName: toPtr("v3.0.0")},
+			}))
+		expected := &upgrade.VersionNotFoundError{Nearby: []string{"v1.7.0", "v1.8.1", "v1.8.2", "v1.9.0", "v2.0.0", "v2.1.0"}, Version: "v1.8.3"}
+		var notFound *upgrade.VersionNotFoundError
+
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", getReleases, upgrade.WithHTTPClient(httpClient), upgrade.WithVersion("1.8.3"))
+
+		// Assert
+		require.ErrorAs(t, err, &notFound)
+		assert.Equal(t, expected, notFound)
+		assert.ErrorIs(t, err, upgrade.ErrNoNewVersion)
+		assert.EqualError(t, err, "version 'v1.8.3' not found (nearby versions: v1.7.0, v1.8.1, v1.8.2, v1.9.0, v2.0.0, v2.1.0)")
+	})
+
+	t.Run("error_invalid_channel_option", func(t *testing.T) {
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", getReleases, upgrade.WithChannel("unknown"))
+
+		// Assert
+		assert.ErrorContains(t, err, upgrade.ErrInvalidOptions.Error())
+		assert.ErrorContains(t, err, "invalid channel 'unknown'")
+	})
+
+	t.Run("error_invalid_retention_option", func(t *testing.T) {
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", getReleases, upgrade.WithRetention(-1))
+
+		// Assert
+		assert.ErrorContains(t, err, upgrade.ErrInvalidOptions.Error())
+		assert.ErrorContains(t, err, "invalid retention")
+	})
+
 	t.Run("error_both_major_minor_options_given", func(t *testing.T) {
 		// Act
 		_, err := upgrade.Run(ctx, "repo", "", getReleases, upgrade.WithMajor("v1"), upgrade.WithMinor("v4.3"))
@@ -153,6 +227,29 @@ func TestRun(t *testing.T) {
 		assert.ErrorContains(t, err, "get asset name")
 	})
 
+	t.Run("error_invalid_checksum_name", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		url := "https://api.github.com/repos/owner/repo/releases?page=1&per_page=100"
+		httpmock.RegisterResponder(http.MethodGet, url,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{
+				{
+					TagName: toPtr("v1.0.0"),
+					Assets: []*github.ReleaseAsset{
+						{Name: toPtr(fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)), BrowserDownloadURL: toPtr("some URL")},
+					},
+				},
+			}))
+
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", getReleases,
+			upgrade.WithChecksumTemplate("{{ func }}"),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		assert.ErrorContains(t, err, "get checksum name")
+	})
+
 	t.Run("error_no_valid_asset", func(t *testing.T) {
 		// Arrange
 		t.Cleanup(httpmock.Reset)
@@ -171,8 +268,8 @@ func TestRun(t *testing.T) {
 		_, err := upgrade.Run(ctx, "repo", "", getReleases, upgrade.WithHTTPClient(httpClient))
 
 		// Assert
-		assert.ErrorContains(t, err, "get download url")
 		assert.ErrorContains(t, err, "no valid release asset found")
+		assert.ErrorContains(t, err, "(candidates: bad asset name)")
 	})
 
 	t.Run("error_download_assets", func(t *testing.T) {
@@ -266,7 +363,6 @@ func TestRun(t *testing.T) {
 					},
 				},
 			}))
-		t.Cleanup(getterCleanup)
 		httpmock.RegisterResponder(http.MethodGet, downloadURL,
 			httpmock.NewStringResponder(http.StatusOK, "some text for a file"))
 
@@ -286,6 +382,289 @@ func TestRun(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, []byte("some text for a file"), bytes)
 	})
+
+	t.Run("success_channel_target", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		releasesURL := "https://api.github.com/repos/owner/repo/releases?page=1&per_page=100"
+		downloadURL := "http://example.com/asset/download/repo"
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{
+				{
+					TagName: toPtr("v1.1.0-preview"),
+					Assets: []*github.ReleaseAsset{
+						{Name: toPtr(fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)), BrowserDownloadURL: &downloadURL},
+					},
+				},
+				{TagName: toPtr("v1.2.0-beta.1")},
+			}))
+		httpmock.RegisterResponder(http.MethodGet, downloadURL,
+			httpmock.NewStringResponder(http.StatusOK, "some text for a file"))
+
+		dest := t.TempDir()
+		ext := map[bool]string{true: ".exe"}[runtime.GOOS == "windows"]
+
+		// Act
+		tag, err := upgrade.Run(ctx, "repo", "v1.0.0", getReleases,
+			upgrade.WithChannel(upgrade.ChannelRC),
+			upgrade.WithDestination(dest),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "v1.1.0-preview", tag)
+		assert.FileExists(t, filepath.Join(dest, "repo-rc"+ext))
+	})
+
+	t.Run("success_keep_versions", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		releasesURL := "https://api.github.com/repos/owner/repo/releases?page=1&per_page=100"
+		downloadURL := "http://example.com/asset/download/repo"
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{
+				{
+					TagName: toPtr("v1.0.0"),
+					Assets: []*github.ReleaseAsset{
+						{Name: toPtr(fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)), BrowserDownloadURL: &downloadURL},
+					},
+				},
+			}))
+		httpmock.RegisterResponder(http.MethodGet, downloadURL,
+			httpmock.NewStringResponder(http.StatusOK, "some text for a file"))
+
+		dest := t.TempDir()
+		ext := map[bool]string{true: ".exe"}[runtime.GOOS == "windows"]
+		for _, name := range []string{"repo-v0.8.0", "repo-v0.9.0", "repo-v1"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dest, name+ext), []byte(name), cfs.RwxRxRxRx))
+		}
+
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "v0.9.0", getReleases,
+			upgrade.WithDestination(dest),
+			upgrade.WithHTTPClient(httpClient),
+			upgrade.WithKeepVersions(true),
+			upgrade.WithRetention(2))
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(filepath.Join(dest, "repo"+ext))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("some text for a file"), bytes)
+		assert.FileExists(t, filepath.Join(dest, "repo-v1.0.0"+ext))
+		assert.FileExists(t, filepath.Join(dest, "repo-v0.9.0"+ext))
+		assert.NoFileExists(t, filepath.Join(dest, "repo-v0.8.0"+ext))
+		assert.FileExists(t, filepath.Join(dest, "repo-v1"+ext)) // not a versioned installation
+	})
+
+	t.Run("error_invalid_health_check_option", func(t *testing.T) {
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", getReleases, upgrade.WithHealthCheck(-time.Second))
+
+		// Assert
+		assert.ErrorContains(t, err, upgrade.ErrInvalidOptions.Error())
+		assert.ErrorContains(t, err, "invalid health check timeout")
+	})
+
+	t.Run("error_self_update_keep_versions", func(t *testing.T) {
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", getReleases, upgrade.WithSelfUpdate(true), upgrade.WithKeepVersions(true))
+
+		// Assert
+		assert.ErrorContains(t, err, upgrade.ErrInvalidOptions.Error())
+		assert.ErrorContains(t, err, upgrade.ErrSelfUpdateKeepVersionsExclusive.Error())
+	})
+
+	t.Run("error_self_update_permission", func(t *testing.T) {
+		if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+			t.Skip("directory permissions can't be restricted")
+		}
+
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		releasesURL := "https://api.github.com/repos/owner/repo/releases?page=1&per_page=100"
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{{TagName: toPtr("v1.0.0")}}))
+
+		dir := t.TempDir()
+		exe := filepath.Join(dir, "repo")
+		require.NoError(t, os.WriteFile(exe, []byte("v0.9.0"), cfs.RwxRxRxRx))
+		require.NoError(t, os.Chmod(dir, 0o555))
+		t.Cleanup(func() { assert.NoError(t, os.Chmod(dir, cfs.RwxRxRxRx)) })
+
+		executable := *upgrade.Executable
+		t.Cleanup(func() { *upgrade.Executable = executable })
+		*upgrade.Executable = func() (string, error) { return exe, nil }
+
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "v0.9.0", getReleases,
+			upgrade.WithHTTPClient(httpClient),
+			upgrade.WithSelfUpdate(true))
+
+		// Assert
+		assert.ErrorIs(t, err, fs.ErrPermission)
+		assert.ErrorContains(t, err, "retry with elevated privileges")
+		assert.Equal(t, 1, httpmock.GetTotalCallCount()) // ensure nothing was downloaded
+	})
+
+	t.Run("success_self_update", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		releasesURL := "https://api.github.com/repos/owner/repo/releases?page=1&per_page=100"
+		downloadURL := "http://example.com/asset/download/repo"
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{
+				{
+					TagName: toPtr("v1.0.0"),
+					Assets: []*github.ReleaseAsset{
+						{Name: toPtr(fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)), BrowserDownloadURL: &downloadURL},
+					},
+				},
+			}))
+		httpmock.RegisterResponder(http.MethodGet, downloadURL,
+			httpmock.NewStringResponder(http.StatusOK, "some text for a file"))
+
+		dir := t.TempDir()
+		ext := map[bool]string{true: ".exe"}[runtime.GOOS == "windows"]
+		exe := filepath.Join(dir, "opt", "repo"+ext)
+		require.NoError(t, os.MkdirAll(filepath.Dir(exe), cfs.RwxRxRxRx))
+		require.NoError(t, os.WriteFile(exe, []byte("v0.9.0"), cfs.RwxRxRxRx))
+		link := filepath.Join(dir, "repo"+ext)
+		if runtime.GOOS == "windows" {
+			link = exe
+		} else {
+			require.NoError(t, os.Symlink(exe, link))
+		}
+
+		executable := *upgrade.Executable
+		t.Cleanup(func() { *upgrade.Executable = executable })
+		*upgrade.Executable = func() (string, error) { return link, nil }
+
+		dest := t.TempDir()
+
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "v0.9.0", getReleases,
+			upgrade.WithDestination(dest),
+			upgrade.WithHTTPClient(httpClient),
+			upgrade.WithSelfUpdate(true))
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(exe)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("some text for a file"), bytes)
+		bytes, err = os.ReadFile(filepath.Join(dir, "opt", "repo.old"+ext))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v0.9.0"), bytes)
+		assert.NoFileExists(t, filepath.Join(dest, "repo"+ext)) // destination is ignored
+	})
+
+	t.Run("error_health_check", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("shell scripts can't be executed")
+		}
+
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		releasesURL := "https://api.github.com/repos/owner/repo/releases?page=1&per_page=100"
+		downloadURL := "http://example.com/asset/download/repo"
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{
+				{
+					TagName: toPtr("v1.0.0"),
+					Assets: []*github.ReleaseAsset{
+						{Name: toPtr(fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)), BrowserDownloadURL: &downloadURL},
+					},
+				},
+			}))
+		httpmock.RegisterResponder(http.MethodGet, downloadURL,
+			httpmock.NewStringResponder(http.StatusOK, "#!/bin/sh\necho 'repo version 0.9.0'\n"))
+
+		dest := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dest, "repo"), []byte("previous"), cfs.RwxRxRxRx))
+
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "v0.9.0", getReleases,
+			upgrade.WithDestination(dest),
+			upgrade.WithHealthCheck(time.Second, "version"),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrHealthCheck)
+		assert.ErrorContains(t, err, "output doesn't contain version 'v1.0.0'")
+		bytes, err := os.ReadFile(filepath.Join(dest, "repo"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("previous"), bytes)
+		assert.NoFileExists(t, filepath.Join(dest, "repo.old"))
+	})
+
+	t.Run("success_health_check", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("shell scripts can't be executed")
+		}
+
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		releasesURL := "https://api.github.com/repos/owner/repo/releases?page=1&per_page=100"
+		downloadURL := "http://example.com/asset/download/repo"
+		httpmock.RegisterResponder(http.MethodGet, releasesURL,
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, []*github.RepositoryRelease{
+				{
+					TagName: toPtr("v1.0.0"),
+					Assets: []*github.ReleaseAsset{
+						{Name: toPtr(fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)), BrowserDownloadURL: &downloadURL},
+					},
+				},
+			}))
+		script := "#!/bin/sh\necho \"repo version 1.0.0 ($1)\"\n"
+		httpmock.RegisterResponder(http.MethodGet, downloadURL, httpmock.NewStringResponder(http.StatusOK, script))
+
+		dest := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dest, "repo"), []byte("previous"), cfs.RwxRxRxRx))
+
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "v0.9.0", getReleases,
+			upgrade.WithDestination(dest),
+			upgrade.WithHealthCheck(0),
+			upgrade.WithHTTPClient(httpClient))
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(filepath.Join(dest, "repo"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte(script), bytes)
+		assert.NoFileExists(t, filepath.Join(dest, "repo.old"))
+	})
+
+	t.Run("success_go_install", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("shell scripts can't be executed")
+		}
+
+		// Arrange
+		mod := module.Version{Path: "example.com/owner/repo/v2", Version: "v2.0.0"}
+		t.Setenv("GOPROXY", fileProxy(t, mod, map[string]string{"go.mod": "module example.com/owner/repo/v2\n"}))
+
+		// fake go command "building" a binary containing its arguments
+		bin := t.TempDir()
+		script := "#!/bin/sh\nprintf '%s' \"$*\" > \"$GOBIN/repo\"\n"
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "go"), []byte(script), cfs.RwxRxRxRx))
+		t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		dest := t.TempDir()
+
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", upgrade.GoProxyReleases(mod.Path),
+			upgrade.WithDestination(dest),
+			upgrade.WithGoInstall(mod.Path),
+			upgrade.WithTargetTemplate("{{ .Repo }}"))
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(filepath.Join(dest, "repo"))
+		require.NoError(t, err)
+		assert.Equal(t, "install example.com/owner/repo/v2@v2.0.0", string(bytes))
+	})
 }
 
 func TestFindRelease(t *testing.T) {
@@ -349,6 +728,159 @@ func TestFindRelease(t *testing.T) {
 		assert.Equal(t, &upgrade.Release{TagName: "v2.5.8"}, release)
 	})
 
+	t.Run("success_constraint_option", func(t *testing.T) {
+		// Arrange
+		releases := []upgrade.Release{
+			{TagName: "v1.3.0"},
+			{TagName: "v1.6.2"},
+			{TagName: "v1.7.0"},
+			{TagName: "v2.0.0"},
+		}
+		constraint, err := upgrade.ParseConstraint(">=1.4.0, <2.0.0, !=1.7.0")
+		require.NoError(t, err)
+
+		// Act
+		release, ok := upgrade.FindRelease(releases, upgrade.ReleaseOptions{Constraint: constraint})
+
+		// Assert
+		assert.True(t, ok)
+		assert.Equal(t, &upgrade.Release{TagName: "v1.6.2"}, release)
+	})
+
+	t.Run("success_version_option", func(t *testing.T) {
+		// Arrange
+		releases := []upgrade.Release{
+			{TagName: "v1.7.0"},
+			{TagName: "v1.8.3-beta.1"},
+			{TagName: "v1.8.3"},
+			{TagName: "v2.0.0"},
+		}
+
+		// Act
+		release, ok := upgrade.FindRelease(releases, upgrade.ReleaseOptions{Version: "v1.8.3"})
+
+		// Assert
+		assert.True(t, ok)
+		assert.Equal(t, &upgrade.Release{TagName: "v1.8.3"}, release)
+		assert.Equal(t, "v1.7.0", releases[0].TagName) // ensure input releases aren't modified
+	})
+
+	t.Run("success_skip_drafts", func(t *testing.T) {
+		// Arrange
+		releases := []upgrade.Release{
+			{TagName: "v1.0.0"},
+			{TagName: "v1.1.0", Draft: true},
+		}
+
+		// Act
+		release, ok := upgrade.FindRelease(releases, upgrade.ReleaseOptions{Prereleases: true})
+
+		// Assert
+		assert.True(t, ok)
+		assert.Equal(t, &upgrade.Release{TagName: "v1.0.0"}, release)
+	})
+
+	t.Run("success_prerelease_flag", func(t *testing.T) {
+		// Arrange
+		releases := []upgrade.Release{
+			{TagName: "v1.0.0"},
+			{TagName: "v2.0.0", Prerelease: true},
+		}
+
+		// Act
+		release, ok := upgrade.FindRelease(releases, upgrade.ReleaseOptions{})
+
+		// Assert
+		assert.True(t, ok)
+		assert.Equal(t, &upgrade.Release{TagName: "v1.0.0"}, release)
+	})
+
+	t.Run("success_no_v_prefix", func(t *testing.T) {
+		// Arrange
+		releases := []upgrade.Release{
+			{TagName: "1.2.3"},
+			{TagName: "v1.10.0"},
+			{TagName: "1.11.0"},
+			{TagName: "invalid"},
+		}
+
+		// Act
+		release, ok := upgrade.FindRelease(releases, upgrade.ReleaseOptions{})
+
+		// Assert
+		assert.True(t, ok)
+		assert.Equal(t, &upgrade.Release{TagName: "1.11.0"}, release)
+	})
+
+	t.Run("success_tag_prefix_option", func(t *testing.T) {
+		// Arrange
+		releases := []upgrade.Release{
+			{TagName: "mytool/v1.2.3"},
+			{TagName: "mytool/v1.3.0"},
+			{TagName: "othertool/v2.0.0"},
+			{TagName: "v3.0.0"},
+		}
+
+		// Act
+		release, ok := upgrade.FindRelease(releases, upgrade.ReleaseOptions{Major: "v1", TagPrefix: "mytool/"})
+
+		// Assert
+		assert.True(t, ok)
+		assert.Equal(t, &upgrade.Release{TagName: "mytool/v1.3.0"}, release)
+	})
+
+	t.Run("success_tag_prefix_option_no_v_prefix", func(t *testing.T) {
+		// Arrange
+		releases := []upgrade.Release{
+			{TagName: "mytool-1.2.3"},
+			{TagName: "mytool-v1.3.0-beta.1"},
+			{TagName: "othertool-2.0.0"},
+		}
+
+		// Act
+		release, ok := upgrade.FindRelease(releases, upgrade.ReleaseOptions{TagPrefix: "mytool-"})
+
+		// Assert
+		assert.True(t, ok)
+		assert.Equal(t, &upgrade.Release{TagName: "mytool-1.2.3"}, release)
+	})
+
+	t.Run("success_channel_option", func(t *testing.T) {
+		// Arrange
+		releases := []upgrade.Release{
+			{TagName: "v1.0.0"},
+			{TagName: "v1.1.0-rc.1"},
+			{TagName: "v1.2.0-beta.1"},
+			{TagName: "v1.3.0-alpha.1"},
+			{TagName: "v1.4.0-nightly.20240601"},
+		}
+
+		// Act
+		release, ok := upgrade.FindRelease(releases, upgrade.ReleaseOptions{Channel: upgrade.ChannelBeta})
+
+		// Assert
+		assert.True(t, ok)
+		assert.Equal(t, &upgrade.Release{TagName: "v1.2.0-beta.1"}, release)
+	})
+
+	t.Run("success_channel_option_rc", func(t *testing.T) {
+		// Arrange
+		releases := []upgrade.Release{
+			{TagName: "v1.0.0"},
+			{TagName: "v1.1.0-pre.1"},
+			{TagName: "v1.2.0", Prerelease: true},
+			{TagName: "v1.3.0-alpha.1"},
+			{TagName: "v1.4.0-snapshot"},
+		}
+
+		// Act
+		release, ok := upgrade.FindRelease(releases, upgrade.ReleaseOptions{Channel: upgrade.ChannelRC, Prereleases: true})
+
+		// Assert
+		assert.True(t, ok)
+		assert.Equal(t, &upgrade.Release{TagName: "v1.2.0", Prerelease: true}, release)
+	})
+
 	t.Run("success_prerelease_option", func(t *testing.T) {
 		// Arrange
 		releases := []upgrade.Release{
@@ -424,53 +956,187 @@ func TestFindRelease(t *testing.T) {
 }
 
 func TestGetDownloadURL(t *testing.T) {
+	ctx := context.Background()
+
+	// setup checksums mocking
+	httpClient := cleanhttp.DefaultClient()
+	httpmock.ActivateNonDefault(httpClient)
+	t.Cleanup(httpmock.DeactivateAndReset)
+
+	asset := fmt.Sprintf("%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	sha256 := strings.Repeat("a", 64)
+	sha512 := strings.Repeat("b", 128)
+
 	t.Run("error_no_valid_asset", func(t *testing.T) {
 		// Arrange
 		release := &upgrade.Release{}
 
 		// Act
-		_, err := upgrade.GetDownloadURL(release, "")
+		_, err := upgrade.GetDownloadURL(ctx, httpClient, nil, release, "", "")
 
 		// Assert
 		assert.ErrorContains(t, err, "no valid release asset found")
 	})
 
+	t.Run("error_get_checksums", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, "https://example.com/checksums.txt",
+			httpmock.NewStringResponder(http.StatusNotFound, "not found"))
+		release := &upgrade.Release{
+			Assets: []upgrade.Asset{
+				{DownloadURL: "https://example.com/checksums.txt", Name: "checksums.txt"},
+				{DownloadURL: "tar.gz URL", Name: asset},
+			},
+		}
+
+		// Act
+		_, err := upgrade.GetDownloadURL(ctx, httpClient, nil, release, asset, "")
+
+		// Assert
+		assert.ErrorContains(t, err, "get checksums")
+	})
+
+	t.Run("error_not_in_checksums", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, "https://example.com/checksums.txt",
+			httpmock.NewStringResponder(http.StatusOK, sha256+"  other.tar.gz\n"))
+		release := &upgrade.Release{
+			Assets: []upgrade.Asset{
+				{DownloadURL: "https://example.com/checksums.txt", Name: "checksums.txt"},
+				{DownloadURL: "tar.gz URL", Name: asset},
+			},
+		}
+
+		// Act
+		_, err := upgrade.GetDownloadURL(ctx, httpClient, nil, release, asset, "")
+
+		// Assert
+		assert.ErrorContains(t, err, "no checksum found")
+	})
+
 	t.Run("success_without_checksum", func(t *testing.T) {
 		// Arrange
 		release := &upgrade.Release{
 			Assets: []upgrade.Asset{
 				{DownloadURL: "zip URL", Name: fmt.Sprintf("%s_%s.zip", runtime.GOOS, runtime.GOARCH)},
-				{DownloadURL: "tar.gz URL", Name: fmt.Sprintf("%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)},
+				{DownloadURL: "tar.gz URL", Name: asset},
 				{DownloadURL: "deb URL", Name: fmt.Sprintf("%s_%s.deb", runtime.GOOS, runtime.GOARCH)},
 				{DownloadURL: "apk URL", Name: fmt.Sprintf("%s_%s.apk", runtime.GOOS, runtime.GOARCH)},
 			},
 		}
 
 		// Act
-		url, err := upgrade.GetDownloadURL(release, fmt.Sprintf("%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH))
+		bin, err := upgrade.GetDownloadURL(ctx, httpClient, nil, release, asset, "")
 
 		// Assert
 		require.NoError(t, err)
-		assert.Equal(t, "tar.gz URL", url)
+		assert.Equal(t, "tar.gz URL", bin.DownloadURL)
 	})
 
 	t.Run("success_with_checksum", func(t *testing.T) {
 		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, "https://example.com/checksums.txt",
+			httpmock.NewStringResponder(http.StatusOK, sha512+"  other.tar.gz\n"+sha256+"  "+asset+"\n"))
 		release := &upgrade.Release{
 			Assets: []upgrade.Asset{
 				{DownloadURL: "apk URL", Name: fmt.Sprintf("%s_%s.apk", runtime.GOOS, runtime.GOARCH)},
-				{DownloadURL: "checksum URL", Name: "checksums.txt"},
+				{DownloadURL: "https://example.com/checksums.txt", Name: "checksums.txt"},
 				{DownloadURL: "deb URL", Name: fmt.Sprintf("%s_%s.deb", runtime.GOOS, runtime.GOARCH)},
-				{DownloadURL: "tar.gz URL", Name: fmt.Sprintf("%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)},
+				{DownloadURL: "tar.gz URL", Name: asset},
 				{DownloadURL: "zip URL", Name: fmt.Sprintf("%s_%s.zip", runtime.GOOS, runtime.GOARCH)},
 			},
 		}
 
 		// Act
-		url, err := upgrade.GetDownloadURL(release, fmt.Sprintf("%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH))
+		bin, err := upgrade.GetDownloadURL(ctx, httpClient, nil, release, asset, "")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "tar.gz URL?checksum=sha256:"+sha256, bin.DownloadURL)
+	})
+
+	t.Run("success_with_project_checksums", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, "https://example.com/repo_1.0.0_checksums.txt",
+			httpmock.NewStringResponder(http.StatusOK, "SHA512 ("+asset+") = "+sha512+"\n"))
+		release := &upgrade.Release{
+			Assets: []upgrade.Asset{
+				{DownloadURL: "https://example.com/repo_1.0.0_checksums.txt", Name: "repo_1.0.0_checksums.txt"},
+				{DownloadURL: "tar.gz URL?query=value", Name: asset},
+			},
+		}
+
+		// Act
+		bin, err := upgrade.GetDownloadURL(ctx, httpClient, nil, release, asset, "")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "tar.gz URL?query=value&checksum=sha512:"+sha512, bin.DownloadURL)
+	})
+
+	t.Run("success_with_sums", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, "https://example.com/SHA256SUMS",
+			httpmock.NewStringResponder(http.StatusOK, sha256+" *"+asset+"\n"))
+		release := &upgrade.Release{
+			Assets: []upgrade.Asset{
+				{DownloadURL: "https://example.com/SHA256SUMS", Name: "SHA256SUMS"},
+				{DownloadURL: "tar.gz URL", Name: asset},
+			},
+		}
+
+		// Act
+		bin, err := upgrade.GetDownloadURL(ctx, httpClient, nil, release, asset, "")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "tar.gz URL?checksum=sha256:"+sha256, bin.DownloadURL)
+	})
+
+	t.Run("success_with_asset_checksum", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, "https://example.com/asset.sha512",
+			httpmock.NewStringResponder(http.StatusOK, strings.ToUpper(sha512)+"\n"))
+		release := &upgrade.Release{
+			Assets: []upgrade.Asset{
+				{DownloadURL: "https://example.com/asset.sha256", Name: asset + ".sha256"},
+				{DownloadURL: "https://example.com/asset.sha512", Name: asset + ".sha512"},
+				{DownloadURL: "tar.gz URL", Name: asset},
+			},
+		}
+
+		// Act
+		bin, err := upgrade.GetDownloadURL(ctx, httpClient, nil, release, asset, "")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "tar.gz URL?checksum=sha512:"+sha512, bin.DownloadURL)
+	})
+
+	t.Run("success_with_checksum_name", func(t *testing.T) {
+		// Arrange
+		t.Cleanup(httpmock.Reset)
+		httpmock.RegisterResponder(http.MethodGet, "https://example.com/hashes",
+			httpmock.NewStringResponder(http.StatusOK, sha256+"  dist/"+asset+"\n"))
+		release := &upgrade.Release{
+			Assets: []upgrade.Asset{
+				{DownloadURL: "https://example.com/checksums.txt", Name: "checksums.txt"},
+				{DownloadURL: "https://example.com/hashes", Name: asset + ".hashes"},
+				{DownloadURL: "tar.gz URL", Name: asset},
+			},
+		}
+
+		// Act
+		bin, err := upgrade.GetDownloadURL(ctx, httpClient, nil, release, asset, asset+".hashes")
 
 		// Assert
 		require.NoError(t, err)
-		assert.Equal(t, "tar.gz URL?checksum=file:checksum URL", url)
+		assert.Equal(t, "tar.gz URL?checksum=sha256:"+sha256, bin.DownloadURL)
 	})
 }
@@ -0,0 +1,23 @@
+package upgrade
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// _executable returns the path of the running executable (overridable for testing purposes).
+var _executable = os.Executable
+
+// executable returns the real path (symbolic links are followed) of the running executable.
+func executable() (string, error) {
+	exe, err := _executable()
+	if err != nil {
+		return "", fmt.Errorf("get executable: %w", err)
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return "", fmt.Errorf("eval symlinks: %w", err)
+	}
+	return exe, nil
+}
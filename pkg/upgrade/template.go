@@ -26,6 +26,19 @@ func getTemplateValue(value string, data map[string]any) (string, error) {
 	return buf.String(), nil
 }
 
+// getTemplateValues returns the parsed result of all input template values.
+func getTemplateValues(values []string, data map[string]any) ([]string, error) {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		parsed, err := getTemplateValue(value, data)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, parsed)
+	}
+	return result, nil
+}
+
 // funcMap returns the text/template function map for upgrade templating (asset name, target name, etc.).
 func funcMap() template.FuncMap {
 	return template.FuncMap{
@@ -0,0 +1,87 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotInstalled is the error returned by Uninstall when there's no recorded installation.
+var ErrNotInstalled = errors.New("no installation recorded")
+
+// Uninstall removes the binary installed by the last Run (of the same repo) alongside all other installed files
+// (other binaries with WithBinaryPath and companions like completions or man pages with WithCompanion) and their backups (see Rollback).
+//
+// Installations are recorded in the state directory (see WithStateDir, the same one must be given to Uninstall).
+// With WithKeepVersions, all versioned installations are removed too.
+// With WithVersionedLayout, all installed versions are removed (i.e. '<root>/<repo>').
+//
+// ErrNoStateDir is returned when no state directory is given and ErrNotInstalled when no installation was recorded.
+// Nothing is removed when ctx is done. Other options are ignored.
+func Uninstall(ctx context.Context, repo string, opts ...RunOption) error {
+	if repo == "" {
+		return ErrNoProjectName
+	}
+
+	ro, err := newRunOpt(opts...)
+	if err != nil {
+		return err
+	}
+	if ro.stateDir == "" {
+		return ErrNoStateDir
+	}
+
+	file := ro.installFile(repo)
+	record, err := readInstallRecord(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrNotInstalled
+		}
+		return fmt.Errorf("read install record: %w", err)
+	}
+
+	paths := append([]string{record.Path, oldPath(record.Path)}, record.Files...)
+	if record.Backup != "" {
+		paths = append(paths, record.Backup)
+	}
+	for _, old := range record.Backups {
+		paths = append(paths, old)
+	}
+	if record.Versioned {
+		versions, err := installedVersions(record.Path)
+		if err != nil {
+			return fmt.Errorf("list versions: %w", err)
+		}
+		for _, version := range versions {
+			paths = append(paths, versionedPath(record.Path, version))
+		}
+	}
+
+	// nothing is removed when ctx is already done
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("remove: %w", err))
+		}
+	}
+	if record.Dir != "" {
+		if err := os.RemoveAll(filepath.Dir(record.Dir)); err != nil {
+			errs = append(errs, fmt.Errorf("remove all: %w", err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...) // keep the record to allow another try
+	}
+
+	if err := os.Remove(file); err != nil {
+		return fmt.Errorf("remove install record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package upgrade_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+func TestUninstall(t *testing.T) {
+	ctx := context.Background()
+
+	root := t.TempDir()
+	asset := fmt.Sprintf("repo_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	writeArchive(t, filepath.Join(root, "v1.2.3", asset), map[string]string{
+		"repo":                  "repo binary",
+		"repo-server":           "server binary",
+		"completions/repo.bash": "bash completion",
+	})
+	getReleases := upgrade.DirReleases(root)
+
+	t.Run("error_missing_project_name", func(t *testing.T) {
+		// Act
+		err := upgrade.Uninstall(ctx, "")
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrNoProjectName)
+	})
+
+	t.Run("error_not_installed", func(t *testing.T) {
+		// Act
+		err := upgrade.Uninstall(ctx, "repo", upgrade.WithStateDir(t.TempDir()))
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrNotInstalled)
+	})
+
+	t.Run("error_invalid_record", func(t *testing.T) {
+		// Arrange
+		state := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(state, "repo.install.json"), []byte("{"), cfs.RwRR))
+
+		// Act
+		err := upgrade.Uninstall(ctx, "repo", upgrade.WithStateDir(state))
+
+		// Assert
+		assert.ErrorContains(t, err, "read install record")
+	})
+
+	t.Run("error_canceled", func(t *testing.T) {
+		// Arrange
+		state := t.TempDir()
+		record := fmt.Sprintf(`{"path":%q,"version":"v1.2.3"}`, filepath.Join(t.TempDir(), "repo"))
+		require.NoError(t, os.WriteFile(filepath.Join(state, "repo.install.json"), []byte(record), cfs.RwRR))
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		// Act
+		err := upgrade.Uninstall(ctx, "repo", upgrade.WithStateDir(state))
+
+		// Assert
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.FileExists(t, filepath.Join(state, "repo.install.json"))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// Arrange
+		data := t.TempDir()
+		t.Setenv("XDG_DATA_HOME", data)
+		dest := t.TempDir()
+		state := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dest, "repo"), []byte("previous"), cfs.RwxRxRxRx))
+
+		_, err := upgrade.Run(ctx, "repo", "v1.0.0", getReleases,
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz"),
+			upgrade.WithBinaryPath("repo", "repo-server"),
+			upgrade.WithCompletions("completions/*.bash", "", ""),
+			upgrade.WithDestination(dest),
+			upgrade.WithStateDir(state),
+			upgrade.WithTargetTemplate("{{ .Repo }}"))
+		require.NoError(t, err)
+		require.FileExists(t, filepath.Join(data, "bash-completion", "completions", "repo.bash"))
+
+		// Act
+		err = upgrade.Uninstall(ctx, "repo", upgrade.WithStateDir(state))
+
+		// Assert
+		require.NoError(t, err)
+		assert.NoFileExists(t, filepath.Join(dest, "repo"))
+		assert.NoFileExists(t, filepath.Join(dest, "repo.old"))
+		assert.NoFileExists(t, filepath.Join(dest, "repo-server"))
+		assert.NoFileExists(t, filepath.Join(data, "bash-completion", "completions", "repo.bash"))
+		assert.ErrorIs(t, upgrade.Uninstall(ctx, "repo", upgrade.WithStateDir(state)), upgrade.ErrNotInstalled)
+	})
+
+	t.Run("success_keep_versions", func(t *testing.T) {
+		// Arrange
+		ext := map[bool]string{true: ".exe"}[runtime.GOOS == "windows"]
+		root := t.TempDir()
+		for _, version := range []string{"v1.0.0", "v1.1.0", "v1.2.0"} {
+			writeArchive(t, filepath.Join(root, version, asset), map[string]string{"repo" + ext: "repo " + version})
+		}
+		dest := t.TempDir()
+		state := t.TempDir()
+		for _, version := range []string{"v1.0.0", "v1.1.0", "v1.2.0"} {
+			_, err := upgrade.Run(ctx, "repo", "", upgrade.DirReleases(root),
+				upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz"),
+				upgrade.WithDestination(dest),
+				upgrade.WithKeepVersions(true),
+				upgrade.WithStateDir(state),
+				upgrade.WithTargetTemplate("{{ .Repo }}{{ .BinExt }}"),
+				upgrade.WithVersion(version))
+			require.NoError(t, err)
+		}
+		require.FileExists(t, filepath.Join(dest, "repo-v1.0.0"+ext))
+
+		// Act
+		err := upgrade.Uninstall(ctx, "repo", upgrade.WithStateDir(state))
+
+		// Assert
+		require.NoError(t, err)
+		entries, err := os.ReadDir(dest)
+		require.NoError(t, err)
+		assert.Empty(t, entries) // all versions and backups are removed
+	})
+
+	t.Run("success_after_rollback", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+		state := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dest, "repo"), []byte("previous"), cfs.RwxRxRxRx))
+
+		_, err := upgrade.Run(ctx, "repo", "v1.0.0", getReleases,
+			upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}.tar.gz"),
+			upgrade.WithBinaryPath("repo", "repo-server"),
+			upgrade.WithDestination(dest),
+			upgrade.WithStateDir(state),
+			upgrade.WithTargetTemplate("{{ .Repo }}"))
+		require.NoError(t, err)
+		_, err = upgrade.Rollback(ctx, "repo", upgrade.WithStateDir(state))
+		require.NoError(t, err)
+
+		// Act
+		err = upgrade.Uninstall(ctx, "repo", upgrade.WithStateDir(state))
+
+		// Assert
+		require.NoError(t, err)
+		assert.NoFileExists(t, filepath.Join(dest, "repo"))
+		assert.NoFileExists(t, filepath.Join(dest, "repo-server"))
+	})
+}
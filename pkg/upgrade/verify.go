@@ -0,0 +1,190 @@
+package upgrade
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// VerificationError is the error returned by Run when the verification of a release asset fails
+// (missing checksums file or signature, invalid signature, asset not listed in checksums file, etc.).
+type VerificationError struct {
+	Asset string
+	Err   error
+}
+
+var _ error = (*VerificationError)(nil) // ensure interface is implemented
+
+// Error implements error.
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("verification of '%s' failed: %v", e.Asset, e.Err)
+}
+
+// Unwrap returns the underlying verification error.
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// Verifier represents a signature verifier of a release checksums file.
+//
+// When given with WithVerifier, the checksums file and its signature are downloaded and verified before downloading the asset,
+// the asset is then verified against its (signed) checksum.
+type Verifier interface {
+	// Signature returns the signature asset name of the input checksums asset name (e.g. checksums.txt.sig for checksums.txt).
+	Signature(checksums string) string
+
+	// Verify verifies that signature is a valid signature of content.
+	Verify(content, signature []byte) error
+}
+
+// verifyChecksums verifies the signature of the checksums file (with the input content) with verifier.
+func verifyChecksums(ctx context.Context, httpClient *http.Client, release *Release, checksums Asset, content []byte, verifier Verifier) error {
+	signature, ok := findAsset(release, verifier.Signature(checksums.Name))
+	if !ok {
+		return &VerificationError{Asset: checksums.Name, Err: fmt.Errorf("no signature '%s' found in release assets", verifier.Signature(checksums.Name))}
+	}
+
+	sig, err := getBytes(ctx, httpClient, signature)
+	if err != nil {
+		return fmt.Errorf("get signature: %w", err)
+	}
+
+	if err := verifier.Verify(content, sig); err != nil {
+		return &VerificationError{Asset: checksums.Name, Err: err}
+	}
+	return nil
+}
+
+// minisignVerifier is a Verifier implementation for minisign signatures (https://jedisct1.github.io/minisign/).
+type minisignVerifier struct {
+	keyID     []byte
+	publicKey ed25519.PublicKey
+}
+
+var _ Verifier = (*minisignVerifier)(nil) // ensure interface is implemented
+
+// newMinisignVerifier parses the input minisign public key (either the whole public key file or only its base64 line).
+func newMinisignVerifier(publicKey string) (*minisignVerifier, error) {
+	lines := strings.Split(strings.TrimSpace(publicKey), "\n")
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[len(lines)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("decode minisign public key: %w", err)
+	}
+	if len(key) != 2+8+ed25519.PublicKeySize || string(key[:2]) != "Ed" {
+		return nil, errors.New("invalid minisign public key")
+	}
+	return &minisignVerifier{keyID: key[2:10], publicKey: key[10:]}, nil
+}
+
+// Signature implements Verifier.
+func (*minisignVerifier) Signature(checksums string) string {
+	return checksums + ".minisig"
+}
+
+// Verify implements Verifier.
+func (v *minisignVerifier) Verify(content, signature []byte) error {
+	// signature file is made of four lines: untrusted comment, signature, trusted comment and global signature
+	lines := strings.Split(strings.TrimSpace(string(signature)), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return errors.New("invalid minisign signature format")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return errors.New("invalid minisign signature")
+	}
+	if !bytes.Equal(sig[2:10], v.keyID) {
+		return errors.New("minisign signature key id doesn't match public key id")
+	}
+
+	message := content
+	switch string(sig[:2]) {
+	case "Ed": // legacy signature
+	case "ED": // prehashed signature
+		hash := blake2b.Sum512(content)
+		message = hash[:]
+	default:
+		return fmt.Errorf("unsupported minisign signature algorithm '%s'", string(sig[:2]))
+	}
+	if !ed25519.Verify(v.publicKey, message, sig[10:]) {
+		return errors.New("invalid minisign signature")
+	}
+
+	// verify trusted comment
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil {
+		return errors.New("invalid minisign global signature")
+	}
+	comment := strings.TrimSuffix(strings.TrimPrefix(lines[2], "trusted comment: "), "\r")
+	if !ed25519.Verify(v.publicKey, slices.Concat(sig[10:], []byte(comment)), global) {
+		return errors.New("invalid minisign global signature")
+	}
+	return nil
+}
+
+// cosignVerifier is a Verifier implementation for cosign keyed blob signatures (cosign sign-blob --key).
+type cosignVerifier struct {
+	publicKey crypto.PublicKey
+}
+
+var _ Verifier = (*cosignVerifier)(nil) // ensure interface is implemented
+
+// newCosignVerifier parses the input PEM encoded public key (ECDSA, ED25519 or RSA).
+func newCosignVerifier(publicKey []byte) (*cosignVerifier, error) {
+	block, _ := pem.Decode(publicKey)
+	if block == nil {
+		return nil, errors.New("invalid cosign public key: no PEM block found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse cosign public key: %w", err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+		return &cosignVerifier{publicKey: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported cosign public key type '%T'", key)
+	}
+}
+
+// Signature implements Verifier.
+func (*cosignVerifier) Signature(checksums string) string {
+	return checksums + ".sig"
+}
+
+// Verify implements Verifier.
+func (v *cosignVerifier) Verify(content, signature []byte) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("decode cosign signature: %w", err)
+	}
+
+	hash := sha256.Sum256(content)
+	var ok bool
+	switch key := v.publicKey.(type) {
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(key, hash[:], sig)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(key, content, sig)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig) == nil
+	}
+	if !ok {
+		return errors.New("invalid cosign signature")
+	}
+	return nil
+}
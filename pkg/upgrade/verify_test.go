@@ -0,0 +1,235 @@
+package upgrade_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/md5" //nolint:gosec // weak checksums rejection
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+	"github.com/kilianpaquier/cli-sdk/pkg/upgrade"
+)
+
+// minisign returns a minisign public key and a function to sign contents with its private key (prehashed signature).
+func minisign(t *testing.T) (string, func(content []byte) []byte) {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keyID := []byte("01234567")
+
+	sign := func(content []byte) []byte {
+		hash := blake2b.Sum512(content)
+		sig := slices.Concat([]byte("ED"), keyID, ed25519.Sign(private, hash[:]))
+		comment := "timestamp:1700000000"
+		global := ed25519.Sign(private, slices.Concat(sig[10:], []byte(comment)))
+		return []byte(fmt.Sprintf("untrusted comment: signature\n%s\ntrusted comment: %s\n%s\n",
+			base64.StdEncoding.EncodeToString(sig), comment, base64.StdEncoding.EncodeToString(global)))
+	}
+	return "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(slices.Concat([]byte("Ed"), keyID, public)), sign
+}
+
+// cosign returns a cosign PEM public key and a function to sign contents with its private key.
+func cosign(t *testing.T) ([]byte, func(content []byte) []byte) {
+	t.Helper()
+
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	public, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	require.NoError(t, err)
+
+	sign := func(content []byte) []byte {
+		hash := sha256.Sum256(content)
+		sig, err := ecdsa.SignASN1(rand.Reader, private, hash[:])
+		require.NoError(t, err)
+		return []byte(base64.StdEncoding.EncodeToString(sig))
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}), sign
+}
+
+func TestWithVerifier(t *testing.T) {
+	ctx := context.Background()
+
+	binary := fmt.Sprintf("repo_%s_%s", runtime.GOOS, runtime.GOARCH)
+	content := []byte("some binary")
+	hash := sha256.Sum256(content)
+	checksums := []byte(hex.EncodeToString(hash[:]) + "  " + binary + "\n")
+
+	// release creates a release directory with the binary (unless given), checksums and given files
+	release := func(t *testing.T, files map[string][]byte) upgrade.GetReleases {
+		t.Helper()
+
+		root := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "v1.0.0"), cfs.RwxRxRxRx))
+		if _, ok := files[binary]; !ok {
+			files[binary] = content
+		}
+		for name, bytes := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(root, "v1.0.0", name), bytes, cfs.RwRR))
+		}
+		return upgrade.DirReleases(root)
+	}
+	asset := upgrade.WithAssetTemplate("{{ .Repo }}_{{ .GOOS }}_{{ .GOARCH }}")
+
+	publicKey, sign := minisign(t)
+
+	t.Run("error_invalid_minisign_key", func(t *testing.T) {
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", release(t, map[string][]byte{}), upgrade.WithMinisign("invalid"))
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrInvalidOptions)
+		assert.ErrorContains(t, err, "minisign public key")
+	})
+
+	t.Run("error_invalid_cosign_key", func(t *testing.T) {
+		// Act
+		_, err := upgrade.Run(ctx, "repo", "", release(t, map[string][]byte{}), upgrade.WithCosign([]byte("invalid")))
+
+		// Assert
+		assert.ErrorIs(t, err, upgrade.ErrInvalidOptions)
+		assert.ErrorContains(t, err, "cosign public key")
+	})
+
+	t.Run("error_missing_checksums", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+
+		// Act
+		err := runDir(t, release(t, map[string][]byte{}), dest, asset, upgrade.WithMinisign(publicKey))
+
+		// Assert
+		var verr *upgrade.VerificationError
+		require.ErrorAs(t, err, &verr)
+		assert.Equal(t, binary, verr.Asset)
+		assert.NoFileExists(t, filepath.Join(dest, "repo"))
+	})
+
+	t.Run("error_missing_signature", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+
+		// Act
+		err := runDir(t, release(t, map[string][]byte{"checksums.txt": checksums}), dest, asset, upgrade.WithMinisign(publicKey))
+
+		// Assert
+		var verr *upgrade.VerificationError
+		require.ErrorAs(t, err, &verr)
+		assert.ErrorContains(t, err, "checksums.txt.minisig")
+	})
+
+	t.Run("error_invalid_signature", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+		getReleases := release(t, map[string][]byte{
+			"checksums.txt":         []byte(hex.EncodeToString(make([]byte, 32)) + "  " + binary + "\n"), // tampered checksums
+			"checksums.txt.minisig": sign(checksums),
+		})
+
+		// Act
+		err := runDir(t, getReleases, dest, asset, upgrade.WithMinisign(publicKey))
+
+		// Assert
+		var verr *upgrade.VerificationError
+		require.ErrorAs(t, err, &verr)
+		assert.Equal(t, "checksums.txt", verr.Asset)
+		assert.NoFileExists(t, filepath.Join(dest, "repo"))
+	})
+
+	t.Run("error_tampered_asset", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+		getReleases := release(t, map[string][]byte{
+			binary:                  []byte("tampered binary"),
+			"checksums.txt":         checksums,
+			"checksums.txt.minisig": sign(checksums),
+		})
+
+		// Act
+		err := runDir(t, getReleases, dest, asset, upgrade.WithMinisign(publicKey))
+
+		// Assert
+		var verr *upgrade.VerificationError
+		require.ErrorAs(t, err, &verr)
+		assert.Equal(t, binary, verr.Asset)
+		assert.NoFileExists(t, filepath.Join(dest, "repo"))
+	})
+
+	t.Run("error_not_in_checksums", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+		other := []byte(hex.EncodeToString(hash[:]) + "  other\n")
+		getReleases := release(t, map[string][]byte{"checksums.txt": other, "checksums.txt.minisig": sign(other)})
+
+		// Act
+		err := runDir(t, getReleases, dest, asset, upgrade.WithMinisign(publicKey))
+
+		// Assert
+		var verr *upgrade.VerificationError
+		require.ErrorAs(t, err, &verr)
+		assert.ErrorContains(t, err, "no checksum found")
+	})
+
+	t.Run("error_weak_checksum", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+		hash := md5.Sum(content)
+		weak := []byte(hex.EncodeToString(hash[:]) + "  " + binary + "\n")
+		getReleases := release(t, map[string][]byte{"checksums.txt": weak, "checksums.txt.minisig": sign(weak)})
+
+		// Act
+		err := runDir(t, getReleases, dest, asset, upgrade.WithMinisign(publicKey))
+
+		// Assert
+		var verr *upgrade.VerificationError
+		require.ErrorAs(t, err, &verr)
+		assert.ErrorContains(t, err, "weak checksum algorithm 'md5'")
+		assert.NoFileExists(t, filepath.Join(dest, "repo"))
+	})
+
+	t.Run("success_minisign", func(t *testing.T) {
+		// Arrange
+		dest := t.TempDir()
+		getReleases := release(t, map[string][]byte{"checksums.txt": checksums, "checksums.txt.minisig": sign(checksums)})
+
+		// Act
+		err := runDir(t, getReleases, dest, asset, upgrade.WithMinisign(publicKey))
+
+		// Assert
+		require.NoError(t, err)
+		bytes, err := os.ReadFile(filepath.Join(dest, "repo"))
+		require.NoError(t, err)
+		assert.Equal(t, content, bytes)
+	})
+
+	t.Run("success_cosign", func(t *testing.T) {
+		// Arrange
+		publicKey, sign := cosign(t)
+		dest := t.TempDir()
+		getReleases := release(t, map[string][]byte{"checksums.txt": checksums, "checksums.txt.sig": sign(checksums)})
+
+		// Act
+		err := runDir(t, getReleases, dest, asset, upgrade.WithCosign(publicKey))
+
+		// Assert
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(dest, "repo"))
+	})
+}
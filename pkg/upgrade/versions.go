@@ -0,0 +1,157 @@
+package upgrade
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/semver"
+
+	"github.com/kilianpaquier/cli-sdk/pkg/cfs"
+)
+
+// versionedPath returns the path of the versioned installation of dest for the given tag.
+//
+// For instance, '/home/user/.local/bin/repo' with tag 'v1.4.2' gives '/home/user/.local/bin/repo-v1.4.2'
+// and 'C:\Users\user\repo.exe' gives 'C:\Users\user\repo-v1.4.2.exe'.
+func versionedPath(dest, tag string) string {
+	return strings.TrimSuffix(dest, binExt()) + "-" + tag + binExt()
+}
+
+// link points dest to the provided versioned installation.
+//
+// A relative symbolic link is created (and atomically replaces dest) on all platforms except windows
+// where symbolic links often require elevated privileges, in which case versioned is copied to dest.
+func link(versioned, dest string) error {
+	if runtime.GOOS == "windows" {
+		if err := cfs.SafeMove(versioned, dest, cfs.WithPerm(cfs.RwxRxRxRx)); err != nil {
+			return fmt.Errorf("safe move: %w", err)
+		}
+		return nil
+	}
+
+	tdest := dest + "_"
+	if err := os.Remove(tdest); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove: %w", err)
+	}
+	rel, err := filepath.Rel(filepath.Dir(dest), versioned)
+	if err != nil {
+		rel = versioned
+	}
+	if err := os.Symlink(rel, tdest); err != nil {
+		return fmt.Errorf("symlink: %w", err)
+	}
+	if err := os.Rename(tdest, dest); err != nil {
+		return fmt.Errorf("move: %w", err)
+	}
+	return nil
+}
+
+// backupLink preserves dest into 'dest.old' (or 'dest.old.exe' on windows) before it's replaced and returns the backup path.
+//
+// When dest is a symbolic link (see WithKeepVersions and WithVersionedLayout), the backup is a symbolic link to the same version,
+// as such restoring it (see Rollback) is only a symbolic link flip. Otherwise it behaves like backup.
+func backupLink(dest string) (string, error) {
+	target := linkTarget(dest)
+	if target == "" {
+		return backup(dest)
+	}
+	old := oldPath(dest)
+	if err := link(target, old); err != nil {
+		return "", fmt.Errorf("link backup: %w", err)
+	}
+	return old, nil
+}
+
+// linkTarget returns the path the symbolic link dest points to (relative targets being resolved against dest directory),
+// or an empty string when dest isn't a symbolic link.
+func linkTarget(dest string) string {
+	if dest == "" {
+		return ""
+	}
+	target, err := os.Readlink(dest)
+	if err != nil {
+		return ""
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(dest), target)
+	}
+	return target
+}
+
+// installedVersions returns the versions of all versioned installations of dest (see versionedPath).
+func installedVersions(dest string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(dest))
+	if err != nil {
+		return nil, fmt.Errorf("read dir: %w", err)
+	}
+
+	prefix := strings.TrimSuffix(filepath.Base(dest), binExt()) + "-"
+	var versions []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) || !strings.HasSuffix(entry.Name(), binExt()) {
+			continue
+		}
+		version := strings.TrimSuffix(strings.TrimPrefix(entry.Name(), prefix), binExt())
+		// ensure version is a full semver version (vX.Y.Z) to avoid removing other targets like 'repo-v1' or 'repo-v1.6'
+		core, _, _ := strings.Cut(version, "-")
+		if !semver.IsValid(version) || strings.Count(core, ".") != 2 {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// pruneVersions removes the oldest versioned installations of dest
+// to only keep retention versions (current one included, see prune).
+func pruneVersions(dest, current, backedUp string, retention int) error {
+	versions, err := installedVersions(dest)
+	if err != nil {
+		return err
+	}
+
+	candidates := make(map[string]string, len(versions))
+	for _, version := range versions {
+		candidates[version] = versionedPath(dest, version)
+	}
+	return prune(candidates, current, backedUp, retention)
+}
+
+// prune removes the paths of the oldest candidates (version to path) to only keep retention versions (current one included).
+//
+// The current path is never removed, even when it's not the newest one (downgrade), as is the backed up one (see Rollback).
+func prune(candidates map[string]string, current, backedUp string, retention int) error {
+	if retention <= 0 {
+		return nil
+	}
+
+	versions := make([]string, 0, len(candidates))
+	for version := range candidates {
+		versions = append(versions, version)
+	}
+	// sort versions from newest to oldest (candidates being a map, equal versions like 'v1.0.0' and 'v1.0.0+build' are sorted lexically)
+	slices.SortFunc(versions, func(v1, v2 string) int { return cmp.Or(semver.Compare(v2, v1), strings.Compare(v1, v2)) })
+
+	kept := 1 // current version is always kept
+	var errs []error
+	for _, version := range versions {
+		p := candidates[version]
+		if p == current || p == backedUp {
+			continue
+		}
+		if kept < retention {
+			kept++
+			continue
+		}
+		if err := os.RemoveAll(p); err != nil {
+			errs = append(errs, fmt.Errorf("remove all: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}